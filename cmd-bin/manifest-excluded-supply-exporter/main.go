@@ -1,3 +1,6 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
 package main
 
 import manifestexcludedsupplyexporter "github.com/manifest-network/manifest-node-exporter/cmd/manifest-excluded-supply-exporter"
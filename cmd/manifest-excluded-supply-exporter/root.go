@@ -1,3 +1,6 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
 package manifest_excluded_supply_exporter
 
 import (
@@ -1,8 +1,10 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
 package manifest_excluded_supply_exporter
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -16,7 +18,7 @@ import (
 
 	"github.com/manifest-network/manifest-node-exporter/pkg"
 	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect"
-	_ "github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect/manifestd" // RegisterMonitor the manifestd monitor (side-effect)
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect/manifestd" // Also registers the manifestd monitor (side-effect)
 )
 
 // serveCmd represents the serve command
@@ -32,19 +34,27 @@ var serveCmd = &cobra.Command{
 		slog.Info("Starting manifest-excluded-supply-exporter")
 
 		config := pkg.LoadServeConfig()
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
 
 		rootCtx, rootCancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 		defer rootCancel()
 
-		monitorCollectors, err := setupMonitors(rootCtx)
+		// Setup process monitors. Their collectors are (un)registered as processes come and go.
+		supervisor, err := autodetect.NewSupervisor(prometheus.DefaultRegisterer, autodetect.GetAllMonitors(), config.DetectInterval)
 		if err != nil {
 			return fmt.Errorf("failed to setup monitors: %w", err)
 		}
-
-		registerCollectors(monitorCollectors)
+		supervisorDone := make(chan struct{})
+		go func() {
+			defer close(supervisorDone)
+			supervisor.Run(rootCtx)
+		}()
 
 		// Setup and start metrics server
 		metricsSrv := pkg.NewMetricsServer(config.ListenAddress)
+		metricsSrv.Handle("/supply/", manifestd.NewSupplyHandler(viper.GetDuration("supply-cache-ttl"), pkg.SupplyTimeout))
 		serverErrChan := metricsSrv.Start()
 
 		// Wait for server errors or shutdown signal
@@ -52,6 +62,7 @@ var serveCmd = &cobra.Command{
 		case err := <-serverErrChan:
 			slog.Error("Metrics server encountered an error", "error", err)
 			rootCancel()
+			<-supervisorDone
 			return fmt.Errorf("metrics server failed: %w", err)
 
 		case <-rootCtx.Done():
@@ -64,6 +75,7 @@ var serveCmd = &cobra.Command{
 			} else {
 				slog.Info("Metrics server has shut down.")
 			}
+			<-supervisorDone
 		}
 
 		slog.Info("Application shut down complete.")
@@ -72,72 +84,13 @@ var serveCmd = &cobra.Command{
 	},
 }
 
-// setupMonitors initializes and sets up all registered process monitors.
-// It detects the processes and collects the corresponding Prometheus collectors.
-func setupMonitors(ctx context.Context) ([]prometheus.Collector, error) {
-	var allCollectors []prometheus.Collector
-	registeredMonitors := autodetect.GetAllMonitors()
-	if len(registeredMonitors) == 0 {
-		return nil, fmt.Errorf("no registered monitors found")
-	} else {
-		slog.Info("Registered monitors", "count", len(registeredMonitors))
-		for _, monitor := range registeredMonitors {
-			slog.Debug("Monitor", "name", monitor.Name())
-		}
-	}
-
-	for _, monitor := range registeredMonitors {
-		slog.Info("Attempting to detect process", "name", monitor.Name())
-		processInfo, err := monitor.Detect()
-		if err != nil {
-			slog.Error("Failed to detect process", "name", monitor.Name(), "error", err)
-			continue
-		}
-
-		if processInfo == nil {
-			continue
-		}
-
-		collectors, err := monitor.CollectCollectors(ctx, processInfo)
-		if err != nil {
-			slog.Error("Failed to collect collectors", "name", monitor.Name(), "error", err)
-			continue
-		}
-		allCollectors = append(allCollectors, collectors...)
-	}
-
-	if len(allCollectors) == 0 {
-		slog.Warn("No collectors found for any registered monitors")
-	}
-
-	return allCollectors, nil
-
-}
-
-// registerCollectors registers the provided Prometheus collectors with the default Prometheus registry.
-func registerCollectors(collectors []prometheus.Collector) {
-	for _, collector := range collectors {
-		collectorType := fmt.Sprintf("%T", collector) // Get type for logging
-		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
-			var alreadyRegistered prometheus.AlreadyRegisteredError
-			if errors.As(err, &alreadyRegistered) {
-				slog.Debug("Collector already registered with Prometheus, skipping registration.", "collector_type", collectorType)
-			} else {
-				slog.Error("Failed to register collector with Prometheus", "collector_type", collectorType, "error", err)
-			}
-		} else {
-			slog.Info("Successfully registered collector with Prometheus.", "collector_type", collectorType)
-		}
-	}
-}
-
 func init() {
 	serveCmd.Flags().String("listen-address", "0.0.0.0:2112", "Address to listen on")
-	serveCmd.Flags().String("addrs-endpoint", "", "HTTP endpoint to fetch address list")
-
-	if err := serveCmd.MarkFlagRequired("addrs-endpoint"); err != nil {
-		slog.Error("Failed to mark addrs-endpoint as required", "error", err)
-	}
+	serveCmd.Flags().Duration("detect-interval", 30*time.Second, "Interval between process detection runs")
+	serveCmd.Flags().Duration("async-interval", 0, "Refresh collectors in the background at this interval and serve cached snapshots on scrape (0 disables)")
+	serveCmd.Flags().String("manifestd-grpc", "", "Explicit manifestd gRPC endpoint (host:port), bypassing process autodetection")
+	serveCmd.Flags().String("addrs-endpoint", "", "HTTP endpoint to fetch address list, in addition to the sources of the configuration file")
+	serveCmd.Flags().Duration("supply-cache-ttl", 30*time.Second, "How long the supply API caches the computed supply")
 
 	if err := viper.BindPFlags(serveCmd.Flags()); err != nil {
 		slog.Error("Failed to bind serveCmd flags", "error", err)
@@ -34,6 +34,9 @@ var serveCmd = &cobra.Command{
 		slog.Info("Starting manifest-node-exporter")
 
 		config := pkg.LoadServeConfig()
+		if err := config.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
 
 		rootCtx, rootCancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 		defer rootCancel()
@@ -43,18 +46,23 @@ var serveCmd = &cobra.Command{
 			slog.Warn("No ipbase API key specified. Skipping GeoIP collection.")
 		} else {
 			geoIpCollector := collectors.NewGeoIPCollector(config.IpBaseKey, config.StateFile)
-			allCollectors = append(allCollectors, geoIpCollector)
+			allCollectors = append(allCollectors, collectors.NewInstrumentedCollector("geoip", geoIpCollector))
 		}
 
-		// Setup process monitors and fetch all registered collectors
-		monitorCollectors, err := setupMonitors(rootCtx)
+		// Register all static collectors with Prometheus
+		registerCollectors(allCollectors)
+
+		// Setup process monitors. Their collectors are (un)registered as processes come and go.
+		supervisor, err := autodetect.NewSupervisor(prometheus.DefaultRegisterer, autodetect.GetAllMonitors(), config.DetectInterval)
 		if err != nil {
 			return fmt.Errorf("failed to setup monitors: %w", err)
 		}
-		allCollectors = append(allCollectors, monitorCollectors...)
-
-		// Register all collectors with Prometheus
-		registerCollectors(allCollectors)
+		supervisor.EnableProcessCollectors()
+		supervisorDone := make(chan struct{})
+		go func() {
+			defer close(supervisorDone)
+			supervisor.Run(rootCtx)
+		}()
 
 		// Setup and start metrics server
 		metricsSrv := pkg.NewMetricsServer(config.ListenAddress)
@@ -65,6 +73,7 @@ var serveCmd = &cobra.Command{
 		case err := <-serverErrChan:
 			slog.Error("Metrics server encountered an error", "error", err)
 			rootCancel()
+			<-supervisorDone
 			return fmt.Errorf("metrics server failed: %w", err)
 
 		case <-rootCtx.Done():
@@ -77,6 +86,7 @@ var serveCmd = &cobra.Command{
 			} else {
 				slog.Info("Metrics server has shut down.")
 			}
+			<-supervisorDone
 		}
 
 		slog.Info("Application shut down complete.")
@@ -84,48 +94,6 @@ var serveCmd = &cobra.Command{
 	},
 }
 
-// setupMonitors initializes and sets up all registered process monitors.
-// It detects the processes and collects the corresponding Prometheus collectors.
-func setupMonitors(ctx context.Context) ([]prometheus.Collector, error) {
-	var allCollectors []prometheus.Collector
-	registeredMonitors := autodetect.GetAllMonitors()
-	if len(registeredMonitors) == 0 {
-		return nil, fmt.Errorf("no registered monitors found")
-	} else {
-		slog.Info("Registered monitors", "count", len(registeredMonitors))
-		for _, monitor := range registeredMonitors {
-			slog.Debug("Monitor", "name", monitor.Name())
-		}
-	}
-
-	for _, monitor := range registeredMonitors {
-		slog.Info("Attempting to detect process", "name", monitor.Name())
-		processInfo, err := monitor.Detect()
-		if err != nil {
-			slog.Error("Failed to detect process", "name", monitor.Name(), "error", err)
-			continue
-		}
-
-		if processInfo == nil {
-			continue
-		}
-
-		collectors, err := monitor.CollectCollectors(ctx, processInfo)
-		if err != nil {
-			slog.Error("Failed to collect collectors", "name", monitor.Name(), "error", err)
-			continue
-		}
-		allCollectors = append(allCollectors, collectors...)
-	}
-
-	if len(allCollectors) == 0 {
-		slog.Warn("No collectors found for any registered monitors")
-	}
-
-	return allCollectors, nil
-
-}
-
 // registerCollectors registers the provided Prometheus collectors with the default Prometheus registry.
 func registerCollectors(collectors []prometheus.Collector) {
 	for _, collector := range collectors {
@@ -145,6 +113,11 @@ func registerCollectors(collectors []prometheus.Collector) {
 
 func init() {
 	serveCmd.Flags().String("listen-address", "0.0.0.0:2112", "Address to listen on")
+	serveCmd.Flags().Duration("detect-interval", 30*time.Second, "Interval between process detection runs")
+	serveCmd.Flags().Duration("async-interval", 0, "Refresh collectors in the background at this interval and serve cached snapshots on scrape (0 disables)")
+	serveCmd.Flags().String("manifestd-grpc", "", "Explicit manifestd gRPC endpoint (host:port), bypassing process autodetection")
+	serveCmd.Flags().String("manifestd-rpc", "", "Explicit manifestd CometBFT RPC endpoint (host:port or URL), bypassing RPC port autodetection")
+	serveCmd.Flags().String("ghostcloudd-grpc", "", "Explicit ghostcloudd gRPC endpoint (host:port), bypassing process autodetection")
 	serveCmd.Flags().String("ipbase-key", "", "IPBase API key to use for GeoIP lookup")
 	serveCmd.Flags().String("state-file", "./state.json", "Path to the state file for GeoIP data persistence")
 
@@ -35,6 +35,14 @@ func NewGRPCClient(ctx context.Context, address string) (*GRPCClient, error) {
 	}, nil
 }
 
+// Close closes the underlying gRPC connection.
+func (c *GRPCClient) Close() error {
+	if c == nil || c.Conn == nil {
+		return nil
+	}
+	return c.Conn.Close()
+}
+
 func dial(address string) (*grpc.ClientConn, error) {
 	var opts []grpc.DialOption
 	opts = append(opts, grpc.WithKeepaliveParams(keepaliveParams))
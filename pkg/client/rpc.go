@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"resty.dev/v3"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg"
+)
+
+// RPCClient is a client of the CometBFT JSON-RPC API, using its URI over HTTP interface.
+type RPCClient struct {
+	Ctx      context.Context
+	Endpoint string // Base URL of the RPC API, e.g. http://127.0.0.1:26657
+	client   *resty.Client
+}
+
+// rpcResponse is the envelope of a CometBFT JSON-RPC response.
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// rpcError is the error of a failed CometBFT JSON-RPC call.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func (e *rpcError) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("RPC error %d: %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// NewRPCClient creates a client of the CometBFT RPC API at the given address.
+// The address is either a URL or a host:port pair, in which case plain HTTP is used.
+func NewRPCClient(ctx context.Context, address string) *RPCClient {
+	slog.Info("Initializing CometBFT RPC client...")
+	endpoint := address
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	return &RPCClient{
+		Ctx:      ctx,
+		Endpoint: endpoint,
+		client:   resty.New().SetHeader("Accept", "application/json").SetTimeout(pkg.ClientTimeout),
+	}
+}
+
+// Call invokes an RPC method without parameters and unmarshals its result into result.
+func (c *RPCClient) Call(ctx context.Context, method string, result interface{}) error {
+	url := c.Endpoint + "/" + method
+	resp, err := c.client.R().SetContext(ctx).Get(url)
+	if err != nil {
+		return err
+	}
+
+	var envelope rpcResponse
+	if err := json.Unmarshal(resp.Bytes(), &envelope); err != nil {
+		if resp.IsError() {
+			return fmt.Errorf("request to %s failed: %s", url, resp.Status())
+		}
+		return fmt.Errorf("invalid RPC response from %s: %w", url, err)
+	}
+	if envelope.Error != nil {
+		return envelope.Error
+	}
+	if resp.IsError() {
+		return fmt.Errorf("request to %s failed: %s", url, resp.Status())
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
+
+// Close releases the resources of the underlying HTTP client.
+func (c *RPCClient) Close() error {
+	if c == nil || c.client == nil {
+		return nil
+	}
+	return c.client.Close()
+}
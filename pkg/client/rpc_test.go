@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRPCServer(t *testing.T, handler http.HandlerFunc) *RPCClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	rpcClient := NewRPCClient(context.Background(), server.URL)
+	t.Cleanup(func() { _ = rpcClient.Close() })
+	return rpcClient
+}
+
+func TestNewRPCClientEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"127.0.0.1:26657":         "http://127.0.0.1:26657",
+		"http://127.0.0.1:26657/": "http://127.0.0.1:26657",
+		"https://rpc.example.com": "https://rpc.example.com",
+	}
+	for address, want := range tests {
+		rpcClient := NewRPCClient(context.Background(), address)
+		if rpcClient.Endpoint != want {
+			t.Errorf("NewRPCClient(%q).Endpoint = %q, want %q", address, rpcClient.Endpoint, want)
+		}
+		_ = rpcClient.Close()
+	}
+}
+
+func TestRPCClientCall(t *testing.T) {
+	rpcClient := newRPCServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/num_unconfirmed_txs" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"result":{"n_txs":"3","total_bytes":"512"}}`))
+	})
+
+	var result struct {
+		NTxs       string `json:"n_txs"`
+		TotalBytes string `json:"total_bytes"`
+	}
+	if err := rpcClient.Call(context.Background(), "num_unconfirmed_txs", &result); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if result.NTxs != "3" || result.TotalBytes != "512" {
+		t.Errorf("Call() result = %+v, want n_txs 3 and total_bytes 512", result)
+	}
+}
+
+func TestRPCClientCallErrorEnvelope(t *testing.T) {
+	rpcClient := newRPCServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error","data":"consensus is not running"}}`))
+	})
+
+	err := rpcClient.Call(context.Background(), "dump_consensus_state", &struct{}{})
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Call() error = %v, want an RPC error", err)
+	}
+	if rpcErr.Code != -32603 || rpcErr.Data != "consensus is not running" {
+		t.Errorf("Call() error = %+v, want code -32603 with data", rpcErr)
+	}
+}
+
+func TestRPCClientCallHTTPError(t *testing.T) {
+	rpcClient := newRPCServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+
+	err := rpcClient.Call(context.Background(), "net_info", &struct{}{})
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("Call() error = %v, want a 502 error", err)
+	}
+}
+
+func TestRPCClientCallTimeout(t *testing.T) {
+	rpcClient := newRPCServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := rpcClient.Call(ctx, "net_info", &struct{}{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Call() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Call() returned after %s, want the context timeout", elapsed)
+	}
+}
@@ -3,17 +3,21 @@ package ghostcloudd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net"
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
 
 	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
 	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect"
 )
 
 const processName = "ghostcloudd"
-const defaultPort = 9090 // Default gRPC port for ghostcloudd
+const defaultPort = 9090                   // Default gRPC port for ghostcloudd
+const grpcEndpointKey = "ghostcloudd-grpc" // Configuration key of the explicit gRPC endpoint
 
 // Ensure ghostcloudd implements ProcessMonitor
 var _ autodetect.ProcessMonitor = (*ghostclouddMonitor)(nil)
@@ -30,12 +34,17 @@ func (m *ghostclouddMonitor) Name() string {
 }
 
 // Detect checks if the monitored process is running, validates its gRPC readiness, and retrieves process information.
+// If an explicit gRPC endpoint is configured, process detection is skipped and only the endpoint is validated.
 func (m *ghostclouddMonitor) Detect() (*autodetect.ProcessInfo, error) {
+	if endpoint := viper.GetString(grpcEndpointKey); endpoint != "" {
+		return autodetect.DetectGrpcEndpoint(processName, endpoint)
+	}
 	return autodetect.DetectProcessWithGrpc(processName, defaultPort)
 }
 
 // CollectCollectors gathers all registered Prometheus collectors for the ghostcloudd process using a provided gRPC client.
 // It requires valid process information to establish a gRPC connection.
+// The gRPC client is closed once ctx is cancelled.
 // Returns a slice of Prometheus collectors or an error if the process information is nil or the gRPC client cannot be created.
 func (m *ghostclouddMonitor) CollectCollectors(ctx context.Context, processInfo *autodetect.ProcessInfo) ([]prometheus.Collector, error) {
 	if processInfo == nil {
@@ -49,9 +58,21 @@ func (m *ghostclouddMonitor) CollectCollectors(ctx context.Context, processInfo
 		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
 	}
 
+	// The gRPC client lives as long as the detection context.
+	go func() {
+		<-ctx.Done()
+		if err := grpcClient.Close(); err != nil {
+			slog.Warn("Failed to close gRPC client", "target", target, "error", err)
+		}
+	}()
+
 	var resultCollectors []prometheus.Collector
-	for _, collector := range GetAllCollectorFactories() {
-		resultCollectors = append(resultCollectors, collector(grpcClient))
+	for name, factory := range GetAllCollectorFactories() {
+		if collector := collectors.NewConfiguredCollector(ctx, name, name, func(cfg collectors.CollectorConfig) prometheus.Collector {
+			return factory(grpcClient, cfg)
+		}); collector != nil {
+			resultCollectors = append(resultCollectors, collector)
+		}
 	}
 
 	return resultCollectors, nil
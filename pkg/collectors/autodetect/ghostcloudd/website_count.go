@@ -4,7 +4,9 @@
 package ghostcloudd
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	gcv1beta1 "github.com/liftedinit/ghostcloud/x/ghostcloud/types"
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,12 +22,13 @@ type WebsiteCountCollector struct {
 	grpcClient       *client.GRPCClient
 	websiteCountDesc *prometheus.Desc // Website count
 	upDesc           *prometheus.Desc // gRPC query success
+	timeout          time.Duration
 	initialError     error
 }
 
 // NewWebsiteCountCollector creates a new WebsiteCountCollector.
 // It requires a gRPC client connection to query the bank module.
-func NewWebsiteCountCollector(client *client.GRPCClient) *WebsiteCountCollector {
+func NewWebsiteCountCollector(client *client.GRPCClient, timeout time.Duration) *WebsiteCountCollector {
 	var initialError error
 	if client == nil {
 		initialError = status.Error(codes.Internal, "gRPC client is nil")
@@ -36,6 +39,7 @@ func NewWebsiteCountCollector(client *client.GRPCClient) *WebsiteCountCollector
 	return &WebsiteCountCollector{
 		grpcClient:   client,
 		initialError: initialError,
+		timeout:      timeout,
 		websiteCountDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("ghostcloud", "deployment", "website_count"),
 			"Total number of deployed websites.",
@@ -66,8 +70,11 @@ func (c *WebsiteCountCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
 	gcQueryClient := gcv1beta1.NewQueryClient(c.grpcClient.Conn)
-	metaResp, metaErr := gcQueryClient.Metas(c.grpcClient.Ctx, &gcv1beta1.QueryMetasRequest{})
+	metaResp, metaErr := gcQueryClient.Metas(ctx, &gcv1beta1.QueryMetasRequest{})
 	if metaErr != nil {
 		slog.Error("Failed to query via gRPC", "query", "metadata", "error", metaErr)
 	}
@@ -102,7 +109,7 @@ func (c *WebsiteCountCollector) Collect(ch chan<- prometheus.Metric) {
 }
 
 func init() {
-	RegisterCollectorFactory("website_count", func(grpcClient *client.GRPCClient, extra ...interface{}) prometheus.Collector {
-		return NewWebsiteCountCollector(grpcClient)
+	RegisterCollectorFactory("website_count", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		return NewWebsiteCountCollector(grpcClient, cfg.Timeout)
 	})
 }
@@ -0,0 +1,114 @@
+package manifestd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// queryModuleAccounts returns the address of every module account, keyed by module name.
+func queryModuleAccounts(ctx context.Context, authQueryClient authv1beta1.QueryClient) (map[string]string, error) {
+	resp, err := authQueryClient.ModuleAccounts(ctx, &authv1beta1.QueryModuleAccountsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[string]string, len(resp.GetAccounts()))
+	for _, account := range resp.GetAccounts() {
+		var moduleAccount authv1beta1.ModuleAccount
+		if err := account.UnmarshalTo(&moduleAccount); err != nil {
+			return nil, fmt.Errorf("failed to decode module account: %w", err)
+		}
+		addresses[moduleAccount.Name] = moduleAccount.GetBaseAccount().GetAddress()
+	}
+	return addresses, nil
+}
+
+// queryAllBalances returns the balance of an address for every denom it holds, in base units.
+// All pages are fetched, each page request being bounded by the given timeout.
+func queryAllBalances(ctx context.Context, bankQueryClient bankv1beta1.QueryClient, address string, timeout time.Duration) (map[string]*big.Int, error) {
+	balances := make(map[string]*big.Int)
+	err := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := bankQueryClient.AllBalances(pageCtx, &bankv1beta1.QueryAllBalancesRequest{Address: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, coin := range resp.Balances {
+			amount, err := parseAmount(coin.Amount)
+			if err != nil {
+				return nil, err
+			}
+			balances[coin.Denom] = amount
+		}
+		return resp.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// queryDelegatedAmount returns the amount delegated by an address to all validators, in base units of the bond denom.
+func queryDelegatedAmount(ctx context.Context, stakingQueryClient stakingv1beta1.QueryClient, address string, timeout time.Duration) (*big.Int, error) {
+	total := new(big.Int)
+	err := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := stakingQueryClient.DelegatorDelegations(pageCtx, &stakingv1beta1.QueryDelegatorDelegationsRequest{DelegatorAddr: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, delegation := range resp.DelegationResponses {
+			amount, err := parseAmount(delegation.GetBalance().GetAmount())
+			if err != nil {
+				return nil, err
+			}
+			total.Add(total, amount)
+		}
+		return resp.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// queryUnbondingAmount returns the amount an address is unbonding from all validators, in base units of the bond denom.
+func queryUnbondingAmount(ctx context.Context, stakingQueryClient stakingv1beta1.QueryClient, address string, timeout time.Duration) (*big.Int, error) {
+	total := new(big.Int)
+	err := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := stakingQueryClient.DelegatorUnbondingDelegations(pageCtx, &stakingv1beta1.QueryDelegatorUnbondingDelegationsRequest{DelegatorAddr: address, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, unbonding := range resp.UnbondingResponses {
+			for _, entry := range unbonding.Entries {
+				amount, err := parseAmount(entry.Balance)
+				if err != nil {
+					return nil, err
+				}
+				total.Add(total, amount)
+			}
+		}
+		return resp.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
@@ -0,0 +1,128 @@
+package manifestd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect"
+)
+
+const bech32Prefix = "manifest"                                      // Bech32 prefix of account addresses
+const consensusPrefix = bech32Prefix + "valcons"                     // Bech32 prefix of consensus addresses
+const defaultNodeHome = ".manifest"                                  // Default node home, relative to the user home directory
+const defaultPrivValidatorKeyFile = "config/priv_validator_key.json" // Default validator key path, relative to the node home
+
+// privValidatorKey holds the fields of a `priv_validator_key.json` file used by the collectors.
+type privValidatorKey struct {
+	Address string `json:"address"` // Hex-encoded consensus address
+}
+
+// nodeConfig holds the fields of the `config/config.toml` file of a node used by the collectors.
+type nodeConfig struct {
+	PrivValidatorKeyFile string `toml:"priv_validator_key_file"` // Validator key path, relative to the node home
+}
+
+// processInfoKey is the context key of the detected manifestd process.
+type processInfoKey struct{}
+
+// withProcessInfo returns a copy of ctx holding the detected manifestd process.
+func withProcessInfo(ctx context.Context, processInfo *autodetect.ProcessInfo) context.Context {
+	return context.WithValue(ctx, processInfoKey{}, processInfo)
+}
+
+// processInfoFromContext returns the detected manifestd process held by ctx, or nil.
+func processInfoFromContext(ctx context.Context) *autodetect.ProcessInfo {
+	processInfo, _ := ctx.Value(processInfoKey{}).(*autodetect.ProcessInfo)
+	return processInfo
+}
+
+// resolveNodeHome returns the home directory of the detected manifestd process.
+// The `--home` flag of the process is used if set, otherwise the default node home of the user running the process.
+// An error is returned when the node is not a local process, e.g. when reached through an explicit gRPC endpoint.
+func resolveNodeHome(processInfo *autodetect.ProcessInfo) (string, error) {
+	if processInfo == nil || processInfo.Pid == 0 {
+		return "", fmt.Errorf("no local %s process to read the validator key from, set consensus_addresses or node_home", processName)
+	}
+
+	home, found, err := autodetect.GetProcessFlag(processInfo.Pid, "--home")
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return home, nil
+	}
+
+	userHome, err := autodetect.GetProcessUserHome(processInfo.Pid)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userHome, defaultNodeHome), nil
+}
+
+// privValidatorKeyFile returns the path of the validator key of the node home.
+// The `priv_validator_key_file` setting of `config/config.toml` is used if set, otherwise the default path.
+func privValidatorKeyFile(nodeHome string) (string, error) {
+	keyFile := defaultPrivValidatorKeyFile
+	path := filepath.Join(nodeHome, "config", "config.toml")
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", fmt.Errorf("failed to read node config: %w", err)
+	default:
+		var config nodeConfig
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return "", fmt.Errorf("failed to parse node config %s: %w", path, err)
+		}
+		if config.PrivValidatorKeyFile != "" {
+			keyFile = config.PrivValidatorKeyFile
+		}
+	}
+
+	if filepath.IsAbs(keyFile) {
+		return keyFile, nil
+	}
+	return filepath.Join(nodeHome, keyFile), nil
+}
+
+// consensusAddressFromKeyFile reads the consensus address of the validator whose key is stored in the node home.
+func consensusAddressFromKeyFile(nodeHome string) (string, error) {
+	path, err := privValidatorKeyFile(nodeHome)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read validator key: %w", err)
+	}
+
+	var key privValidatorKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("failed to parse validator key %s: %w", path, err)
+	}
+	addr, err := hex.DecodeString(key.Address)
+	if err != nil || len(addr) == 0 {
+		return "", fmt.Errorf("invalid address in validator key %s", path)
+	}
+	return bech32.EncodeFromBase256(consensusPrefix, addr)
+}
+
+// validateAddress checks that an address is a valid bech32 address with the given prefix.
+func validateAddress(address, prefix string) error {
+	hrp, _, err := bech32.DecodeToBase256(address)
+	if err != nil {
+		return fmt.Errorf("invalid bech32 address %q: %w", address, err)
+	}
+	if hrp != prefix {
+		return fmt.Errorf("invalid bech32 address %q: expected prefix %s, got %s", address, prefix, hrp)
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+const (
+	defaultBlockPollInterval = 2 * time.Second // Default time between two polls of the latest block
+	defaultBlockMaxCatchUp   = 100             // Default maximum number of missed blocks processed after a gap
+	maxBlockPollBackoff      = time.Minute     // Maximum time between two polls while the node is unreachable
+	txResultsPageLimit       = 100             // Number of transactions requested per page of GetTxsEvent
+	maxFailedMessageTypes    = 100             // Maximum number of distinct msg_type labels of the failed transactions
+	otherMessageType         = "other"         // msg_type label of the failed messages beyond maxFailedMessageTypes
+)
+
+// failedMessageIndex matches the index of the failed message in the log of a failed transaction,
+// e.g. "failed to execute message; message index: 1: insufficient funds".
+var failedMessageIndex = regexp.MustCompile(`message index: (\d+)`)
+
+// blocksOptions holds the collector-specific options of the blocks collector.
+type blocksOptions struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"` // Time between two polls of the latest block
+	MaxCatchUp   int64         `mapstructure:"max_catch_up"`  // Maximum number of missed blocks processed after a gap
+}
+
+// txResults holds the execution results of the transactions of a block.
+type txResults struct {
+	gasUsed int64
+	failed  map[[2]string]int // Failed transactions, by module and message type
+}
+
+// BlocksCollector follows the new blocks of the chain and records block time and transaction throughput.
+// Blocks are polled in the background with GetBlockByHeight for as long as the gRPC client context lives,
+// so that no block is missed between two scrapes.
+type BlocksCollector struct {
+	grpcClient    *client.GRPCClient
+	intervalHist  prometheus.Histogram   // Time between consecutive blocks
+	txsHist       prometheus.Histogram   // Transactions per block
+	sizeHist      prometheus.Histogram   // Block size
+	gasHist       prometheus.Histogram   // Gas used per block
+	txsCounter    prometheus.Counter     // Transactions
+	failedCounter *prometheus.CounterVec // Failed transactions, by module and message type
+	heightGauge   prometheus.Gauge       // Last processed block
+	upDesc        *prometheus.Desc       // gRPC query success of the last poll
+	up            atomic.Bool
+	pollInterval  time.Duration
+	maxCatchUp    int64
+	timeout       time.Duration
+	initialError  error
+	last          *blockHeader        // Last processed block, only accessed by the polling goroutine
+	noTxResults   bool                // Whether the last transaction query failed, only accessed by the polling goroutine
+	legacyTxQuery bool                // Whether the node requires the events of the transaction query, only accessed by the polling goroutine
+	msgTypes      map[string]struct{} // msg_type labels in use, only accessed by the polling goroutine
+}
+
+// NewBlocksCollector creates a new BlocksCollector and starts polling the blocks of the node.
+// It requires a gRPC client connection to query the tendermint and tx services. Polling stops when the client context is done.
+// After a gap, e.g. while the node was unreachable, at most maxCatchUp missed blocks are processed.
+// Gas used and failed transactions require the transaction indexer of the node.
+func NewBlocksCollector(client *client.GRPCClient, pollInterval time.Duration, maxCatchUp int64, timeout time.Duration) *BlocksCollector {
+	c := newBlocksCollector(client, pollInterval, maxCatchUp, timeout)
+	if c.initialError == nil {
+		go c.run(client.Ctx)
+	}
+	return c
+}
+
+// newBlocksCollector creates a new BlocksCollector without starting to poll.
+func newBlocksCollector(client *client.GRPCClient, pollInterval time.Duration, maxCatchUp int64, timeout time.Duration) *BlocksCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	} else if pollInterval <= 0 || maxCatchUp <= 0 {
+		initialError = status.Error(codes.InvalidArgument, "poll_interval and max_catch_up must be positive")
+	}
+
+	c := &BlocksCollector{
+		grpcClient:   client,
+		initialError: initialError,
+		pollInterval: pollInterval,
+		maxCatchUp:   maxCatchUp,
+		timeout:      timeout,
+		msgTypes:     make(map[string]struct{}),
+		intervalHist: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "interval_seconds",
+			Help:        "Time between consecutive blocks, from the block header times.",
+			Buckets:     []float64{0.5, 1, 2, 3, 4, 5, 6, 8, 10, 15, 20, 30, 60},
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}),
+		txsHist: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "txs",
+			Help:        "Number of transactions per block.",
+			Buckets:     []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}),
+		sizeHist: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "size_bytes",
+			Help:        "Size of the blocks, in bytes.",
+			Buckets:     prometheus.ExponentialBuckets(1024, 2, 14),
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}),
+		gasHist: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "gas_used",
+			Help:        "Gas used by the transactions of a block.",
+			Buckets:     prometheus.ExponentialBuckets(100000, 2, 12),
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}),
+		txsCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "txs_total",
+			Help:        "Total number of transactions in the processed blocks.",
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}),
+		failedCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "failed_txs_total",
+			Help:        "Total number of failed transactions in the processed blocks, by module (codespace) and type of the failed message.",
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}, []string{"module", "msg_type"}),
+		heightGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "manifest",
+			Subsystem:   "blocks",
+			Name:        "processed_height",
+			Help:        "Height of the last processed block.",
+			ConstLabels: prometheus.Labels{"source": "grpc"},
+		}),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "blocks", "grpc_up"),
+			"Whether the last poll of the blocks was successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "GetLatestBlock, GetBlockByHeight, GetTxsEvent"},
+		),
+	}
+	return c
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *BlocksCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.intervalHist.Describe(ch)
+	c.txsHist.Describe(ch)
+	c.sizeHist.Describe(ch)
+	c.gasHist.Describe(ch)
+	c.txsCounter.Describe(ch)
+	c.failedCounter.Describe(ch)
+	c.heightGauge.Describe(ch)
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *BlocksCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.heightGauge.Desc(), err)
+		return
+	}
+
+	c.intervalHist.Collect(ch)
+	c.txsHist.Collect(ch)
+	c.sizeHist.Collect(ch)
+	c.gasHist.Collect(ch)
+	c.txsCounter.Collect(ch)
+	c.failedCounter.Collect(ch)
+	c.heightGauge.Collect(ch)
+
+	upValue := 0.0
+	if c.up.Load() {
+		upValue = 1.0
+	}
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+// run polls the new blocks until ctx is done.
+// Failed polls are retried with an exponential backoff, resuming from the last processed block.
+func (c *BlocksCollector) run(ctx context.Context) {
+	tmQueryClient := tmv1beta1.NewServiceClient(c.grpcClient.Conn)
+	txClient := txv1beta1.NewServiceClient(c.grpcClient.Conn)
+
+	delay := c.pollInterval
+	for {
+		err := c.poll(ctx, tmQueryClient, txClient)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		delay = c.nextPollDelay(delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// nextPollDelay records the result of a poll and returns the time until the next one.
+// The delay doubles after each failed poll, up to maxBlockPollBackoff, and is reset by a successful one.
+func (c *BlocksCollector) nextPollDelay(delay time.Duration, err error) time.Duration {
+	if err != nil {
+		c.up.Store(false)
+		delay = min(delay*2, maxBlockPollBackoff)
+		slog.Warn("Failed to poll blocks, retrying", "retry_in", delay, "error", err)
+		return delay
+	}
+	c.up.Store(true)
+	return c.pollInterval
+}
+
+// poll processes the blocks produced since the last processed block.
+// The first poll only records the latest block, as the starting point.
+func (c *BlocksCollector) poll(ctx context.Context, tmQueryClient tmv1beta1.ServiceClient, txClient txv1beta1.ServiceClient) error {
+	latest, err := c.queryWithTimeout(ctx, func(ctx context.Context) (*blockHeader, error) {
+		return queryLatestBlockHeader(ctx, tmQueryClient)
+	})
+	if err != nil {
+		return fmt.Errorf("GetLatestBlock: %w", err)
+	}
+	if c.last == nil {
+		c.last = latest
+		c.heightGauge.Set(float64(latest.Height))
+		return nil
+	}
+
+	if gap := latest.Height - c.last.Height; gap > c.maxCatchUp {
+		slog.Warn("Too many missed blocks, skipping", "from", c.last.Height+1, "to", latest.Height-c.maxCatchUp)
+		start := latest.Height - c.maxCatchUp
+		c.last, err = c.queryWithTimeout(ctx, func(ctx context.Context) (*blockHeader, error) {
+			return queryBlockHeader(ctx, tmQueryClient, start)
+		})
+		if err != nil {
+			c.last = nil
+			return fmt.Errorf("GetBlockByHeight: %w", err)
+		}
+	}
+
+	for height := c.last.Height + 1; height <= latest.Height; height++ {
+		if err := c.processBlock(ctx, tmQueryClient, txClient, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processBlock records the metrics of the block at the given height, which must follow the last processed block.
+func (c *BlocksCollector) processBlock(ctx context.Context, tmQueryClient tmv1beta1.ServiceClient, txClient txv1beta1.ServiceClient, height int64) error {
+	queryCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := tmQueryClient.GetBlockByHeight(queryCtx, &tmv1beta1.GetBlockByHeightRequest{Height: height})
+	if err != nil {
+		return fmt.Errorf("GetBlockByHeight: %w", err)
+	}
+	header, err := headerOf(resp)
+	if err != nil {
+		return err
+	}
+
+	var txs [][]byte
+	var size int
+	if block := resp.GetSdkBlock(); block != nil {
+		txs, size = block.GetData().GetTxs(), proto.Size(block)
+	} else {
+		txs, size = resp.GetBlock().GetData().GetTxs(), proto.Size(resp.GetBlock())
+	}
+
+	c.intervalHist.Observe(header.Time.Sub(c.last.Time).Seconds())
+	c.txsHist.Observe(float64(len(txs)))
+	c.sizeHist.Observe(float64(size))
+	c.txsCounter.Add(float64(len(txs)))
+
+	if len(txs) == 0 {
+		c.gasHist.Observe(0)
+	} else if results, err := c.queryTxResults(ctx, txClient, height); err != nil {
+		// Only warn once, as the query keeps failing on nodes without a transaction indexer.
+		if !c.noTxResults {
+			slog.Warn("Failed to query the transactions of the block, gas used and failed transactions are not recorded", "height", height, "error", err)
+		}
+		c.noTxResults = true
+	} else {
+		c.noTxResults = false
+		c.gasHist.Observe(float64(results.gasUsed))
+		for key, count := range results.failed {
+			c.failedCounter.WithLabelValues(key[0], c.messageTypeLabel(key[1])).Add(float64(count))
+		}
+	}
+
+	c.last = header
+	c.heightGauge.Set(float64(height))
+	return nil
+}
+
+// messageTypeLabel returns the msg_type label of a failed message type.
+// The type URLs come from arbitrary transactions, so only the first maxFailedMessageTypes distinct types get
+// their own label, and the others are reported as "other".
+func (c *BlocksCollector) messageTypeLabel(msgType string) string {
+	if _, ok := c.msgTypes[msgType]; ok {
+		return msgType
+	}
+	if len(c.msgTypes) >= maxFailedMessageTypes {
+		return otherMessageType
+	}
+	c.msgTypes[msgType] = struct{}{}
+	return msgType
+}
+
+// queryWithTimeout runs a block header query bounded by the collector timeout.
+func (c *BlocksCollector) queryWithTimeout(ctx context.Context, query func(context.Context) (*blockHeader, error)) (*blockHeader, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return query(queryCtx)
+}
+
+// queryTxResults returns the gas used and the failed transactions of the block at the given height.
+// Nodes before Cosmos SDK 0.50 ignore the query of the request and reject it for missing events: the request is then
+// sent again with the events, which are used for all the following blocks once they succeed.
+func (c *BlocksCollector) queryTxResults(ctx context.Context, txClient txv1beta1.ServiceClient, height int64) (*txResults, error) {
+	if !c.legacyTxQuery {
+		results, err := queryTxResults(ctx, txClient, height, false, c.timeout)
+		if status.Code(err) != codes.InvalidArgument {
+			return results, err
+		}
+		legacyResults, legacyErr := queryTxResults(ctx, txClient, height, true, c.timeout)
+		if legacyErr != nil {
+			return nil, err
+		}
+		slog.Info("Node requires the events of the transaction query, assuming Cosmos SDK before 0.50", "error", err)
+		c.legacyTxQuery = true
+		return legacyResults, nil
+	}
+	return queryTxResults(ctx, txClient, height, true, c.timeout)
+}
+
+// queryTxResults returns the gas used and the failed transactions of the block at the given height.
+// The block is selected by the query of the request (Cosmos SDK 0.50 and later), or by its events if legacy is set.
+// All pages are fetched, each page request being bounded by the given timeout.
+func queryTxResults(ctx context.Context, txClient txv1beta1.ServiceClient, height int64, legacy bool, timeout time.Duration) (*txResults, error) {
+	results := &txResults{failed: make(map[[2]string]int)}
+	var seen uint64
+	for page := uint64(1); ; page++ {
+		req := &txv1beta1.GetTxsEventRequest{
+			OrderBy: txv1beta1.OrderBy_ORDER_BY_ASC,
+			Page:    page,
+			Limit:   txResultsPageLimit,
+		}
+		if legacy {
+			req.Events = []string{fmt.Sprintf("tx.height=%d", height)}
+		} else {
+			req.Query = fmt.Sprintf("tx.height=%d", height)
+		}
+
+		pageCtx, cancel := context.WithTimeout(ctx, timeout)
+		resp, err := txClient.GetTxsEvent(pageCtx, req)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, txResponse := range resp.TxResponses {
+			results.gasUsed += txResponse.GasUsed
+			if txResponse.Code == 0 {
+				continue
+			}
+			msgType := "unknown"
+			if i < len(resp.Txs) {
+				msgType = failedMessageType(resp.Txs[i], txResponse.RawLog)
+			}
+			results.failed[[2]string{txResponse.Codespace, msgType}]++
+		}
+
+		seen += uint64(len(resp.TxResponses))
+		if len(resp.TxResponses) == 0 || seen >= resp.GetTotal() {
+			return results, nil
+		}
+	}
+}
+
+// failedMessageType returns the type of the message that made a transaction fail, from the message index in its log.
+// Failures without a message index, e.g. in the ante handler, are attributed to the message of single-message
+// transactions, and to "unknown" otherwise.
+func failedMessageType(tx *txv1beta1.Tx, rawLog string) string {
+	messages := tx.GetBody().GetMessages()
+	index := -1
+	if match := failedMessageIndex.FindStringSubmatch(rawLog); match != nil {
+		index, _ = strconv.Atoi(match[1])
+	} else if len(messages) == 1 {
+		index = 0
+	}
+	if index < 0 || index >= len(messages) {
+		return "unknown"
+	}
+	return strings.TrimPrefix(messages[index].GetTypeUrl(), "/")
+}
+
+func init() {
+	RegisterCollectorFactory("blocks", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := blocksOptions{PollInterval: defaultBlockPollInterval, MaxCatchUp: defaultBlockMaxCatchUp}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid blocks options", "error", err)
+		}
+		return NewBlocksCollector(grpcClient, opts.PollInterval, opts.MaxCatchUp, cfg.Timeout)
+	})
+}
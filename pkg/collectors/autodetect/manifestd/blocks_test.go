@@ -0,0 +1,417 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	abciv1beta1 "cosmossdk.io/api/cosmos/base/abci/v1beta1"
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	txv1beta1 "cosmossdk.io/api/cosmos/tx/v1beta1"
+	typesv1 "cosmossdk.io/api/tendermint/types"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// blockTime is the time of the block at height 0 served by fakeBlockServer, whose blocks are 5s apart.
+var blockTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeBlockServer serves the blocks of a chain whose latest height is set by the test.
+type fakeBlockServer struct {
+	tmv1beta1.UnimplementedServiceServer
+	mu        sync.Mutex
+	latest    int64
+	txs       map[int64]int // Number of transactions, by height
+	down      bool          // Whether the queries fail
+	requested []int64       // Heights requested with GetBlockByHeight
+}
+
+func (s *fakeBlockServer) set(latest int64, down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest, s.down = latest, down
+}
+
+func (s *fakeBlockServer) requestedHeights() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requested := s.requested
+	s.requested = nil
+	return requested
+}
+
+func (s *fakeBlockServer) block(height int64) *tmv1beta1.Block {
+	data := &typesv1.Data{}
+	for i := 0; i < s.txs[height]; i++ {
+		data.Txs = append(data.Txs, []byte(fmt.Sprintf("tx-%d-%d", height, i)))
+	}
+	return &tmv1beta1.Block{
+		Header: &tmv1beta1.Header{Height: height, Time: timestamppb.New(blockTime.Add(time.Duration(height) * 5 * time.Second))},
+		Data:   data,
+	}
+}
+
+func (s *fakeBlockServer) GetLatestBlock(context.Context, *tmv1beta1.GetLatestBlockRequest) (*tmv1beta1.GetLatestBlockResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "node is down")
+	}
+	return &tmv1beta1.GetLatestBlockResponse{SdkBlock: s.block(s.latest)}, nil
+}
+
+func (s *fakeBlockServer) GetBlockByHeight(_ context.Context, req *tmv1beta1.GetBlockByHeightRequest) (*tmv1beta1.GetBlockByHeightResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, status.Error(codes.Unavailable, "node is down")
+	}
+	s.requested = append(s.requested, req.Height)
+	return &tmv1beta1.GetBlockByHeightResponse{SdkBlock: s.block(req.Height)}, nil
+}
+
+// fakeTxServer serves the given pages of transaction results to GetTxsEvent, whatever the height.
+type fakeTxServer struct {
+	txv1beta1.UnimplementedServiceServer
+	mu        sync.Mutex
+	pages     [][]*abciv1beta1.TxResponse
+	total     uint64
+	legacy    bool     // Whether the server predates the query of the request, selecting transactions by events
+	requested []uint64 // Pages requested with GetTxsEvent
+	rejected  int      // Requests rejected by a legacy server for missing events
+}
+
+func (s *fakeTxServer) GetTxsEvent(_ context.Context, req *txv1beta1.GetTxsEventRequest) (*txv1beta1.GetTxsEventResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.legacy && len(req.Events) == 0 {
+		s.rejected++
+		return nil, status.Error(codes.InvalidArgument, "must declare at least one event to search")
+	}
+	if !s.legacy && req.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query cannot be empty")
+	}
+	s.requested = append(s.requested, req.Page)
+	resp := &txv1beta1.GetTxsEventResponse{Total: s.total}
+	if req.Page >= 1 && int(req.Page) <= len(s.pages) {
+		resp.TxResponses = s.pages[req.Page-1]
+		for range resp.TxResponses {
+			resp.Txs = append(resp.Txs, newTx(sendMsg))
+		}
+	}
+	return resp, nil
+}
+
+// newBlocksTestCollector returns a blocks collector, which does not poll by itself, and the clients of the fake node.
+func newBlocksTestCollector(t *testing.T, blocks *fakeBlockServer, txs *fakeTxServer, maxCatchUp int64) (*BlocksCollector, tmv1beta1.ServiceClient, txv1beta1.ServiceClient) {
+	t.Helper()
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		tmv1beta1.RegisterServiceServer(server, blocks)
+		txv1beta1.RegisterServiceServer(server, txs)
+	})
+	c := newBlocksCollector(grpcClient, 10*time.Millisecond, maxCatchUp, time.Second)
+	if c.initialError != nil {
+		t.Fatalf("initialError = %v", c.initialError)
+	}
+	return c, tmv1beta1.NewServiceClient(grpcClient.Conn), txv1beta1.NewServiceClient(grpcClient.Conn)
+}
+
+// metricValue returns the value of a counter or gauge, or the sample count of a histogram.
+func metricValue(t *testing.T, metric prometheus.Metric) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	switch {
+	case m.Counter != nil:
+		return m.GetCounter().GetValue()
+	case m.Histogram != nil:
+		return float64(m.GetHistogram().GetSampleCount())
+	default:
+		return m.GetGauge().GetValue()
+	}
+}
+
+const sendMsg, delegateMsg = "/cosmos.bank.v1beta1.MsgSend", "/cosmos.staking.v1beta1.MsgDelegate"
+
+func newTx(typeURLs ...string) *txv1beta1.Tx {
+	body := &txv1beta1.TxBody{}
+	for _, typeURL := range typeURLs {
+		body.Messages = append(body.Messages, &anypb.Any{TypeUrl: typeURL})
+	}
+	return &txv1beta1.Tx{Body: body}
+}
+
+func TestFailedMessageType(t *testing.T) {
+	send, delegate := sendMsg, delegateMsg
+
+	tests := []struct {
+		name   string
+		tx     *txv1beta1.Tx
+		rawLog string
+		want   string
+	}{
+		{"second message failed", newTx(send, delegate), "failed to execute message; message index: 1: insufficient funds", "cosmos.staking.v1beta1.MsgDelegate"},
+		{"first message failed", newTx(send, delegate), "failed to execute message; message index: 0: insufficient funds", "cosmos.bank.v1beta1.MsgSend"},
+		{"single message without index", newTx(delegate), "out of gas in location: WriteFlat", "cosmos.staking.v1beta1.MsgDelegate"},
+		{"multiple messages without index", newTx(send, delegate), "insufficient fees", "unknown"},
+		{"index out of range", newTx(send), "message index: 3: invalid", "unknown"},
+		{"no messages", newTx(), "insufficient fees", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := failedMessageType(tt.tx, tt.rawLog); got != tt.want {
+			t.Errorf("%s: failedMessageType() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBlocksPollCatchUp(t *testing.T) {
+	blocks := &fakeBlockServer{latest: 10, txs: map[int64]int{12: 2}}
+	txs := &fakeTxServer{pages: [][]*abciv1beta1.TxResponse{{{GasUsed: 1000}, {GasUsed: 500, Code: 5, Codespace: "bank"}}}, total: 2}
+	c, tmQueryClient, txClient := newBlocksTestCollector(t, blocks, txs, 100)
+	ctx := context.Background()
+
+	// The first poll only records the starting point.
+	if err := c.poll(ctx, tmQueryClient, txClient); err != nil {
+		t.Fatalf("first poll() error = %v", err)
+	}
+	if got := blocks.requestedHeights(); len(got) != 0 {
+		t.Errorf("first poll requested heights %v, want none", got)
+	}
+	if got := metricValue(t, c.heightGauge); got != 10 {
+		t.Errorf("processed_height = %v, want 10", got)
+	}
+
+	// After a gap, every missed block is processed in order.
+	blocks.set(13, false)
+	if err := c.poll(ctx, tmQueryClient, txClient); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if got, want := blocks.requestedHeights(), []int64{11, 12, 13}; !slices.Equal(got, want) {
+		t.Errorf("requested heights = %v, want %v", got, want)
+	}
+	if got := metricValue(t, c.heightGauge); got != 13 {
+		t.Errorf("processed_height = %v, want 13", got)
+	}
+	if got := metricValue(t, c.intervalHist); got != 3 {
+		t.Errorf("interval_seconds count = %v, want 3", got)
+	}
+	if got := metricValue(t, c.txsCounter); got != 2 {
+		t.Errorf("txs_total = %v, want 2", got)
+	}
+	if got := metricValue(t, c.failedCounter.WithLabelValues("bank", "cosmos.bank.v1beta1.MsgSend")); got != 1 {
+		t.Errorf("failed_txs_total{module=bank} = %v, want 1", got)
+	}
+}
+
+func TestBlocksPollMaxCatchUp(t *testing.T) {
+	blocks := &fakeBlockServer{latest: 10}
+	c, tmQueryClient, txClient := newBlocksTestCollector(t, blocks, &fakeTxServer{}, 5)
+	ctx := context.Background()
+
+	if err := c.poll(ctx, tmQueryClient, txClient); err != nil {
+		t.Fatalf("first poll() error = %v", err)
+	}
+
+	// Only the last maxCatchUp blocks are processed, resuming from the header of the block before them.
+	blocks.set(100, false)
+	if err := c.poll(ctx, tmQueryClient, txClient); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if got, want := blocks.requestedHeights(), []int64{95, 96, 97, 98, 99, 100}; !slices.Equal(got, want) {
+		t.Errorf("requested heights = %v, want %v", got, want)
+	}
+	if got := metricValue(t, c.intervalHist); got != 5 {
+		t.Errorf("interval_seconds count = %v, want 5", got)
+	}
+	if got := metricValue(t, c.heightGauge); got != 100 {
+		t.Errorf("processed_height = %v, want 100", got)
+	}
+}
+
+func TestBlocksPollFailureResumes(t *testing.T) {
+	blocks := &fakeBlockServer{latest: 10}
+	c, tmQueryClient, txClient := newBlocksTestCollector(t, blocks, &fakeTxServer{}, 100)
+	ctx := context.Background()
+
+	if err := c.poll(ctx, tmQueryClient, txClient); err != nil {
+		t.Fatalf("first poll() error = %v", err)
+	}
+	blocks.set(12, true)
+	if err := c.poll(ctx, tmQueryClient, txClient); err == nil {
+		t.Fatal("poll() error = nil while the node is down")
+	}
+
+	// The next successful poll resumes from the last processed block.
+	blocks.set(12, false)
+	if err := c.poll(ctx, tmQueryClient, txClient); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if got, want := blocks.requestedHeights(), []int64{11, 12}; !slices.Equal(got, want) {
+		t.Errorf("requested heights = %v, want %v", got, want)
+	}
+}
+
+func TestBlocksNextPollDelay(t *testing.T) {
+	c := newBlocksCollector(nil, 2*time.Second, 100, time.Second)
+	failed := errors.New("unreachable")
+
+	delay := c.pollInterval
+	for _, want := range []time.Duration{4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second, maxBlockPollBackoff, maxBlockPollBackoff} {
+		if delay = c.nextPollDelay(delay, failed); delay != want {
+			t.Errorf("delay after a failed poll = %v, want %v", delay, want)
+		}
+		if c.up.Load() {
+			t.Error("up = true after a failed poll")
+		}
+	}
+	if delay = c.nextPollDelay(delay, nil); delay != 2*time.Second {
+		t.Errorf("delay after a successful poll = %v, want 2s", delay)
+	}
+	if !c.up.Load() {
+		t.Error("up = false after a successful poll")
+	}
+}
+
+func TestBlocksCollectorUp(t *testing.T) {
+	blocks := &fakeBlockServer{latest: 10, down: true}
+	c, _, _ := newBlocksTestCollector(t, blocks, &fakeTxServer{}, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitForUp := func(want float64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got := gatherValues(t, c)["manifest_blocks_grpc_up"]
+			if got == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("manifest_blocks_grpc_up = %v, want %v", got, want)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitForUp(0)
+	blocks.set(11, false)
+	waitForUp(1)
+	blocks.set(12, true)
+	waitForUp(0)
+	blocks.set(13, false)
+	waitForUp(1)
+}
+
+func TestQueryTxResultsPagination(t *testing.T) {
+	failed := func(codespace string) *abciv1beta1.TxResponse {
+		return &abciv1beta1.TxResponse{GasUsed: 10, Code: 1, Codespace: codespace}
+	}
+	ok := &abciv1beta1.TxResponse{GasUsed: 100}
+
+	tests := []struct {
+		name      string
+		pages     [][]*abciv1beta1.TxResponse
+		total     uint64
+		wantPages []uint64
+		wantGas   int64
+		wantFails int
+	}{
+		{"single page", [][]*abciv1beta1.TxResponse{{ok, failed("bank")}}, 2, []uint64{1}, 110, 1},
+		{"stops when all are seen", [][]*abciv1beta1.TxResponse{{ok, ok}, {failed("bank")}, {ok}}, 3, []uint64{1, 2}, 210, 1},
+		{"stops on an empty page", [][]*abciv1beta1.TxResponse{{ok}, {failed("bank"), failed("staking")}}, 10, []uint64{1, 2, 3}, 120, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs := &fakeTxServer{pages: tt.pages, total: tt.total}
+			_, _, txClient := newBlocksTestCollector(t, &fakeBlockServer{}, txs, 100)
+
+			results, err := queryTxResults(context.Background(), txClient, 10, false, time.Second)
+			if err != nil {
+				t.Fatalf("queryTxResults() error = %v", err)
+			}
+			if !slices.Equal(txs.requested, tt.wantPages) {
+				t.Errorf("requested pages = %v, want %v", txs.requested, tt.wantPages)
+			}
+			if results.gasUsed != tt.wantGas {
+				t.Errorf("gasUsed = %d, want %d", results.gasUsed, tt.wantGas)
+			}
+			fails := 0
+			for _, count := range results.failed {
+				fails += count
+			}
+			if fails != tt.wantFails {
+				t.Errorf("failed transactions = %d, want %d", fails, tt.wantFails)
+			}
+		})
+	}
+}
+
+func TestQueryTxResultsLegacyNode(t *testing.T) {
+	ok := &abciv1beta1.TxResponse{GasUsed: 100}
+	tests := []struct {
+		name         string
+		legacy       bool
+		wantRejected int
+	}{
+		{"query", false, 0},
+		{"events before Cosmos SDK 0.50", true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs := &fakeTxServer{pages: [][]*abciv1beta1.TxResponse{{ok}}, total: 1, legacy: tt.legacy}
+			c, _, txClient := newBlocksTestCollector(t, &fakeBlockServer{}, txs, 100)
+
+			// The events are remembered after the first rejected query.
+			for height := int64(10); height < 12; height++ {
+				results, err := c.queryTxResults(context.Background(), txClient, height)
+				if err != nil {
+					t.Fatalf("queryTxResults() error = %v", err)
+				}
+				if results.gasUsed != 100 {
+					t.Errorf("gasUsed = %d, want 100", results.gasUsed)
+				}
+			}
+			if txs.rejected != tt.wantRejected || c.legacyTxQuery != tt.legacy {
+				t.Errorf("rejected, legacyTxQuery = %d, %t, want %d, %t", txs.rejected, c.legacyTxQuery, tt.wantRejected, tt.legacy)
+			}
+		})
+	}
+}
+
+func TestMessageTypeLabel(t *testing.T) {
+	c := newBlocksCollector(nil, time.Second, 100, time.Second)
+	for i := 0; i < maxFailedMessageTypes; i++ {
+		msgType := fmt.Sprintf("spam.v1.Msg%d", i)
+		if got := c.messageTypeLabel(msgType); got != msgType {
+			t.Fatalf("messageTypeLabel(%q) = %q, want it unchanged", msgType, got)
+		}
+	}
+	if got := c.messageTypeLabel("cosmos.bank.v1beta1.MsgSend"); got != otherMessageType {
+		t.Errorf("messageTypeLabel() beyond the cap = %q, want %q", got, otherMessageType)
+	}
+	if got := c.messageTypeLabel("spam.v1.Msg0"); got != "spam.v1.Msg0" {
+		t.Errorf("messageTypeLabel() of a known type = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,201 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// rpcInt is an integer of a CometBFT RPC response, encoded either as a JSON number or as a string.
+type rpcInt int64
+
+func (i *rpcInt) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = rpcInt(value)
+	return nil
+}
+
+// netInfoResult holds the fields of a `net_info` result used by the collector.
+type netInfoResult struct {
+	NPeers rpcInt `json:"n_peers"`
+	Peers  []struct {
+		IsOutbound bool `json:"is_outbound"`
+	} `json:"peers"`
+}
+
+// unconfirmedTxsResult holds the fields of a `num_unconfirmed_txs` result used by the collector.
+type unconfirmedTxsResult struct {
+	NTxs       rpcInt `json:"n_txs"`
+	TotalBytes rpcInt `json:"total_bytes"`
+}
+
+// consensusStateResult holds the fields of a `dump_consensus_state` result used by the collector.
+type consensusStateResult struct {
+	RoundState struct {
+		Height rpcInt `json:"height"`
+		Round  rpcInt `json:"round"`
+		Step   rpcInt `json:"step"`
+	} `json:"round_state"`
+}
+
+// CometBFTCollector collects peer, mempool and consensus metrics from the CometBFT RPC API.
+type CometBFTCollector struct {
+	rpcClient           *client.RPCClient
+	peersDesc           *prometheus.Desc // Connected peers, by direction
+	mempoolSizeDesc     *prometheus.Desc // Unconfirmed transactions
+	mempoolBytesDesc    *prometheus.Desc // Size of the unconfirmed transactions
+	consensusHeightDesc *prometheus.Desc // Height of the consensus state
+	consensusRoundDesc  *prometheus.Desc // Round of the consensus state
+	consensusStepDesc   *prometheus.Desc // Step of the consensus state
+	upDesc              *prometheus.Desc // RPC query success
+	timeout             time.Duration
+	initialError        error
+}
+
+// NewCometBFTCollector creates a new CometBFTCollector.
+// It requires an RPC client of the CometBFT RPC API of the node.
+func NewCometBFTCollector(rpcClient *client.RPCClient, timeout time.Duration) *CometBFTCollector {
+	var initialError error
+	if rpcClient == nil {
+		initialError = status.Error(codes.Internal, "RPC client is nil")
+	}
+
+	return &CometBFTCollector{
+		rpcClient:    rpcClient,
+		initialError: initialError,
+		timeout:      timeout,
+		peersDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "peers"),
+			"Number of connected peers, by direction.",
+			[]string{"direction"},
+			prometheus.Labels{"source": "rpc"},
+		),
+		mempoolSizeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "mempool_txs"),
+			"Number of unconfirmed transactions in the mempool.",
+			nil,
+			prometheus.Labels{"source": "rpc"},
+		),
+		mempoolBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "mempool_bytes"),
+			"Total size of the unconfirmed transactions in the mempool, in bytes.",
+			nil,
+			prometheus.Labels{"source": "rpc"},
+		),
+		consensusHeightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "consensus_height"),
+			"Height the consensus is working on.",
+			nil,
+			prometheus.Labels{"source": "rpc"},
+		),
+		consensusRoundDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "consensus_round"),
+			"Round of the height the consensus is working on.",
+			nil,
+			prometheus.Labels{"source": "rpc"},
+		),
+		consensusStepDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "consensus_step"),
+			"Step of the round the consensus is working on (1 NewHeight, 2 NewRound, 3 Propose, 4 Prevote, 5 PrevoteWait, 6 Precommit, 7 PrecommitWait, 8 Commit).",
+			nil,
+			prometheus.Labels{"source": "rpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "cometbft", "rpc_up"),
+			"Whether the RPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "rpc", "queries": "net_info, num_unconfirmed_txs, dump_consensus_state"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *CometBFTCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.peersDesc
+	ch <- c.mempoolSizeDesc
+	ch <- c.mempoolBytesDesc
+	ch <- c.consensusHeightDesc
+	ch <- c.consensusRoundDesc
+	ch <- c.consensusStepDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *CometBFTCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization errors first.
+	if c.initialError != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report RPC down
+		collectors.ReportInvalidMetric(ch, c.peersDesc, c.initialError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.rpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	upValue := 1.0
+
+	var netInfo netInfoResult
+	if err := c.rpcClient.Call(ctx, "net_info", &netInfo); err != nil {
+		slog.Error("Failed to query via RPC", "query", "net_info", "error", err)
+		collectors.ReportInvalidMetric(ch, c.peersDesc, err)
+		upValue = 0
+	} else {
+		// The direction of the peers is read from the peer list, the number of peers from n_peers when present.
+		total := max(int(netInfo.NPeers), len(netInfo.Peers))
+		var outbound int
+		for _, peer := range netInfo.Peers {
+			if peer.IsOutbound {
+				outbound++
+			}
+		}
+		collectors.ReportGaugeMetric(ch, c.peersDesc, float64(total-outbound), "inbound")
+		collectors.ReportGaugeMetric(ch, c.peersDesc, float64(outbound), "outbound")
+	}
+
+	var unconfirmed unconfirmedTxsResult
+	if err := c.rpcClient.Call(ctx, "num_unconfirmed_txs", &unconfirmed); err != nil {
+		slog.Error("Failed to query via RPC", "query", "num_unconfirmed_txs", "error", err)
+		collectors.ReportInvalidMetric(ch, c.mempoolSizeDesc, err)
+		upValue = 0
+	} else {
+		collectors.ReportGaugeMetric(ch, c.mempoolSizeDesc, float64(unconfirmed.NTxs))
+		collectors.ReportGaugeMetric(ch, c.mempoolBytesDesc, float64(unconfirmed.TotalBytes))
+	}
+
+	var consensus consensusStateResult
+	if err := c.rpcClient.Call(ctx, "dump_consensus_state", &consensus); err != nil {
+		slog.Error("Failed to query via RPC", "query", "dump_consensus_state", "error", err)
+		collectors.ReportInvalidMetric(ch, c.consensusHeightDesc, err)
+		upValue = 0
+	} else {
+		collectors.ReportGaugeMetric(ch, c.consensusHeightDesc, float64(consensus.RoundState.Height))
+		collectors.ReportGaugeMetric(ch, c.consensusRoundDesc, float64(consensus.RoundState.Round))
+		collectors.ReportGaugeMetric(ch, c.consensusStepDesc, float64(consensus.RoundState.Step))
+	}
+
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+func init() {
+	RegisterRPCCollectorFactory("cometbft", func(rpcClient *client.RPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		return NewCometBFTCollector(rpcClient, cfg.Timeout)
+	})
+}
@@ -0,0 +1,111 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+)
+
+// newCometBFTServer starts a CometBFT RPC stand-in serving the given results, keyed by method.
+func newCometBFTServer(t *testing.T, results map[string]string) *client.RPCClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := results[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"error":{"code":-32601,"message":"Method not found"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":-1,"result":` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+
+	rpcClient := client.NewRPCClient(context.Background(), server.URL)
+	t.Cleanup(func() { _ = rpcClient.Close() })
+	return rpcClient
+}
+
+func TestCometBFTCollector(t *testing.T) {
+	tests := []struct {
+		name    string
+		results map[string]string
+	}{
+		{
+			name: "string integers",
+			results: map[string]string{
+				"net_info":             `{"listening":true,"n_peers":"3","peers":[{"is_outbound":true},{"is_outbound":false},{"is_outbound":false}]}`,
+				"num_unconfirmed_txs":  `{"n_txs":"7","total":"7","total_bytes":"2048","txs":null}`,
+				"dump_consensus_state": `{"round_state":{"height":"1234","round":2,"step":6}}`,
+			},
+		},
+		{
+			name: "number integers",
+			results: map[string]string{
+				"net_info":             `{"listening":true,"n_peers":3,"peers":[{"is_outbound":false},{"is_outbound":true},{"is_outbound":false}]}`,
+				"num_unconfirmed_txs":  `{"n_txs":7,"total":7,"total_bytes":2048,"txs":null}`,
+				"dump_consensus_state": `{"round_state":{"height":1234,"round":"2","step":"6"}}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewCometBFTCollector(newCometBFTServer(t, tt.results), time.Second)
+			got := gatherValues(t, collector)
+
+			want := map[string]float64{
+				"manifest_cometbft_peers{direction=inbound}":  2,
+				"manifest_cometbft_peers{direction=outbound}": 1,
+				"manifest_cometbft_mempool_txs":               7,
+				"manifest_cometbft_mempool_bytes":             2048,
+				"manifest_cometbft_consensus_height":          1234,
+				"manifest_cometbft_consensus_round":           2,
+				"manifest_cometbft_consensus_step":            6,
+				"manifest_cometbft_rpc_up":                    1,
+			}
+			assertValues(t, got, want)
+		})
+	}
+}
+
+func TestCometBFTCollectorFailedQuery(t *testing.T) {
+	rpcClient := newCometBFTServer(t, map[string]string{
+		"net_info":            `{"n_peers":"0","peers":[]}`,
+		"num_unconfirmed_txs": `{"n_txs":"0","total_bytes":"0"}`,
+	})
+	collector := NewCometBFTCollector(rpcClient, time.Second)
+
+	ch := make(chan prometheus.Metric, 16)
+	collector.Collect(ch)
+	close(ch)
+
+	var invalid int
+	up := -1.0
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			invalid++
+			continue
+		}
+		if metric.Desc() == collector.upDesc {
+			up = m.GetGauge().GetValue()
+		}
+	}
+	if invalid != 1 {
+		t.Errorf("invalid metrics = %d, want 1 for dump_consensus_state", invalid)
+	}
+	if up != 0 {
+		t.Errorf("rpc_up = %v, want 0", up)
+	}
+}
@@ -4,9 +4,16 @@
 package manifestd
 
 import (
+	"context"
 	"log/slog"
+	"math/big"
+	"regexp"
+	"time"
 
 	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -15,34 +22,89 @@ import (
 	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
 )
 
+// denomInfoOptions holds the collector-specific options of the denom_metadata collector.
+type denomInfoOptions struct {
+	amountOptions `mapstructure:",squash"`
+	AllDenoms     bool   `mapstructure:"all_denoms"` // Report every denom on chain instead of the configured ones
+	Allow         string `mapstructure:"allow"`      // Only report denoms matching this regex
+	Deny          string `mapstructure:"deny"`       // Never report denoms matching this regex
+}
+
 // DenomInfoCollector collects denom metadata and total supply metrics from the Cosmos SDK bank module via gRPC.
-// Initialize the collector with the denom you want to monitor.
+// All queries of a collection are served at the same block height.
+// Initialize the collector with the denoms you want to monitor, or use NewAllDenomsInfoCollector to monitor every denom.
 type DenomInfoCollector struct {
 	grpcClient      *client.GRPCClient
-	denom           string
+	denoms          []string
+	allDenoms       bool
+	allow           *regexp.Regexp // nil allows every denom
+	deny            *regexp.Regexp // nil denies no denom
+	amountLabels    bool
+	timeout         time.Duration
 	denomMetaDesc   *prometheus.Desc // Denom metadata
 	upDesc          *prometheus.Desc // gRPC query success
-	totalSupplyDesc *prometheus.Desc // Token supply
+	totalSupplyDesc *prometheus.Desc // Token supply, as a label
+	totalAmountDesc *prometheus.Desc // Token supply, in display units
+	heightDesc      *prometheus.Desc // Block height of the queries
 	initialError    error
 }
 
 // NewDenomInfoCollector creates a new DenomInfoCollector.
 // It requires a gRPC client connection to query the bank module.
-func NewDenomInfoCollector(client *client.GRPCClient, denom string) *DenomInfoCollector {
+// If amountLabels is set, the exact supply is also reported as a label of the total_supply metric.
+func NewDenomInfoCollector(client *client.GRPCClient, denoms []string, amountLabels bool, timeout time.Duration) *DenomInfoCollector {
+	c := newDenomInfoCollector(client, amountLabels, timeout, "GetLatestBlock, DenomMetadata, SupplyOf")
+	c.denoms = denoms
+	if c.initialError != nil {
+		return c
+	}
+	if len(denoms) == 0 {
+		c.initialError = status.Error(codes.InvalidArgument, "denoms is empty")
+	}
+	for _, denom := range denoms {
+		if denom == "" {
+			c.initialError = status.Error(codes.InvalidArgument, "denom is empty")
+		}
+	}
+	return c
+}
+
+// NewAllDenomsInfoCollector creates a new DenomInfoCollector reporting every denom on chain.
+// Denoms are reported if they match the allow regex and don't match the deny regex. Empty regexes are ignored.
+func NewAllDenomsInfoCollector(client *client.GRPCClient, allow, deny string, amountLabels bool, timeout time.Duration) *DenomInfoCollector {
+	c := newDenomInfoCollector(client, amountLabels, timeout, "GetLatestBlock, DenomsMetadata, TotalSupply")
+	c.allDenoms = true
+	if c.initialError != nil {
+		return c
+	}
+	var err error
+	if allow != "" {
+		if c.allow, err = regexp.Compile(allow); err != nil {
+			c.initialError = status.Errorf(codes.InvalidArgument, "invalid allow regex: %v", err)
+			return c
+		}
+	}
+	if deny != "" {
+		if c.deny, err = regexp.Compile(deny); err != nil {
+			c.initialError = status.Errorf(codes.InvalidArgument, "invalid deny regex: %v", err)
+		}
+	}
+	return c
+}
+
+func newDenomInfoCollector(client *client.GRPCClient, amountLabels bool, timeout time.Duration, queries string) *DenomInfoCollector {
 	var initialError error
 	if client == nil {
 		initialError = status.Error(codes.Internal, "gRPC client is nil")
 	} else if client.Conn == nil {
 		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
 	}
-	if denom == "" {
-		initialError = status.Error(codes.InvalidArgument, "denom is empty")
-	}
 
 	return &DenomInfoCollector{
 		grpcClient:   client,
 		initialError: initialError,
-		denom:        denom,
+		amountLabels: amountLabels,
+		timeout:      timeout,
 		denomMetaDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "denom_metadata"),
 			"Information about a Cosmos SDK denomination.",
@@ -55,11 +117,23 @@ func NewDenomInfoCollector(client *client.GRPCClient, denom string) *DenomInfoCo
 			[]string{"denom", "supply"},
 			prometheus.Labels{"source": "grpc"},
 		),
+		totalAmountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "total_supply_amount"),
+			"Total supply of a specific denomination, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		heightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "denom_height"),
+			"Block height at which the denom metadata and supply were queried.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
 		upDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "denom_grpc_up"),
 			"Whether the gRPC query was successful.",
 			nil,
-			prometheus.Labels{"source": "grpc", "queries": "DenomMetadata, SupplyOf"},
+			prometheus.Labels{"source": "grpc", "queries": queries},
 		),
 	}
 }
@@ -68,6 +142,8 @@ func NewDenomInfoCollector(client *client.GRPCClient, denom string) *DenomInfoCo
 func (c *DenomInfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.denomMetaDesc
 	ch <- c.totalSupplyDesc
+	ch <- c.totalAmountDesc
+	ch <- c.heightDesc
 	ch <- c.upDesc
 }
 
@@ -77,30 +153,137 @@ func (c *DenomInfoCollector) Collect(ch chan<- prometheus.Metric) {
 	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
 		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
 		collectors.ReportInvalidMetric(ch, c.totalSupplyDesc, err)
+		collectors.ReportInvalidMetric(ch, c.totalAmountDesc, err)
 		collectors.ReportInvalidMetric(ch, c.denomMetaDesc, err)
 		return
 	}
 
+	ctx, latest, err := pinLatestHeight(c.grpcClient.Ctx, tmv1beta1.NewServiceClient(c.grpcClient.Conn), c.timeout)
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetLatestBlock", "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.totalSupplyDesc, err)
+		collectors.ReportInvalidMetric(ch, c.totalAmountDesc, err)
+		collectors.ReportInvalidMetric(ch, c.denomMetaDesc, err)
+		return
+	}
+	collectors.ReportGaugeMetric(ch, c.heightDesc, float64(latest.Height))
+
 	bankQueryClient := bankv1beta1.NewQueryClient(c.grpcClient.Conn)
-	denomMetaResp, denomMetaErr := bankQueryClient.DenomMetadata(c.grpcClient.Ctx, &bankv1beta1.QueryDenomMetadataRequest{Denom: c.denom})
+	upValue := 1.0
+	if c.allDenoms {
+		if !c.collectAllDenoms(ctx, ch, bankQueryClient) {
+			upValue = 0.0
+		}
+	} else {
+		for _, denom := range c.denoms {
+			if !c.collectDenom(ctx, ch, bankQueryClient, denom) {
+				upValue = 0.0
+			}
+		}
+	}
+
+	// Report 'up' metric based on query success
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+// collectDenom queries and reports the metadata and total supply of a single denom.
+// It returns whether all queries succeeded.
+func (c *DenomInfoCollector) collectDenom(ctx context.Context, ch chan<- prometheus.Metric, bankQueryClient bankv1beta1.QueryClient, denom string) bool {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	denomMetaResp, denomMetaErr := bankQueryClient.DenomMetadata(ctx, &bankv1beta1.QueryDenomMetadataRequest{Denom: denom})
 	if denomMetaErr != nil {
-		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "error", denomMetaErr)
+		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "denom", denom, "error", denomMetaErr)
 	}
 
-	totalSupplyResp, totalSupplyErr := bankQueryClient.SupplyOf(c.grpcClient.Ctx, &bankv1beta1.QuerySupplyOfRequest{Denom: c.denom})
+	totalSupply, totalSupplyErr := querySupplyOf(ctx, bankQueryClient, denom)
 	if totalSupplyErr != nil {
-		slog.Error("Failed to query via gRPC", "query", "SupplyOf", "error", totalSupplyErr)
+		slog.Error("Failed to query via gRPC", "query", "SupplyOf", "denom", denom, "error", totalSupplyErr)
 	}
 
-	// Report 'up' metric based on query success
-	upValue := 0.0
-	if denomMetaErr == nil && totalSupplyErr == nil {
-		upValue = 1.0
+	c.collectDenomMetadata(ch, denomMetaResp, denomMetaErr)
+	c.collectTotalSupply(ch, denom, totalSupply, totalSupplyErr, denomMetaResp, denomMetaErr)
+
+	return denomMetaErr == nil && totalSupplyErr == nil
+}
+
+// collectAllDenoms queries and reports the metadata and total supply of every denom on chain.
+// Pagination is followed to completion. It returns whether all queries succeeded.
+func (c *DenomInfoCollector) collectAllDenoms(ctx context.Context, ch chan<- prometheus.Metric, bankQueryClient bankv1beta1.QueryClient) bool {
+	var metadatas []*bankv1beta1.Metadata
+	denomsMetaErr := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		resp, err := bankQueryClient.DenomsMetadata(pageCtx, &bankv1beta1.QueryDenomsMetadataRequest{Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		metadatas = append(metadatas, resp.Metadatas...)
+		return resp.Pagination, nil
+	})
+	if denomsMetaErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "DenomsMetadata", "error", denomsMetaErr)
+		collectors.ReportInvalidMetric(ch, c.denomMetaDesc, denomsMetaErr)
+	} else {
+		for _, metadata := range metadatas {
+			if metadata != nil && c.isDenomAllowed(metadata.Base) {
+				c.reportDenomMetadata(ch, metadata)
+			}
+		}
 	}
-	collectors.ReportUpMetric(ch, c.upDesc, upValue)
 
-	c.collectDenomMetadata(ch, denomMetaResp, denomMetaErr)
-	c.collectTotalSupply(ch, totalSupplyResp, totalSupplyErr)
+	var supply []*basev1beta1.Coin
+	totalSupplyErr := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		resp, err := bankQueryClient.TotalSupply(pageCtx, &bankv1beta1.QueryTotalSupplyRequest{Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		supply = append(supply, resp.Supply...)
+		return resp.Pagination, nil
+	})
+	if totalSupplyErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "TotalSupply", "error", totalSupplyErr)
+		collectors.ReportInvalidMetric(ch, c.totalSupplyDesc, totalSupplyErr)
+		collectors.ReportInvalidMetric(ch, c.totalAmountDesc, totalSupplyErr)
+	} else if denomsMetaErr != nil {
+		// Supply amounts can't be scaled without the metadata.
+		collectors.ReportInvalidMetric(ch, c.totalAmountDesc, denomsMetaErr)
+	} else {
+		metadataByDenom := make(map[string]*bankv1beta1.Metadata, len(metadatas))
+		for _, metadata := range metadatas {
+			if metadata != nil {
+				metadataByDenom[metadata.Base] = metadata
+			}
+		}
+		for _, coin := range supply {
+			if coin == nil || !c.isDenomAllowed(coin.Denom) {
+				continue
+			}
+			amount, err := parseAmount(coin.Amount)
+			if err != nil {
+				slog.Error("Failed to parse total supply", "denom", coin.Denom, "error", err)
+				collectors.ReportInvalidMetric(ch, c.totalAmountDesc, err)
+				continue
+			}
+			c.reportTotalSupply(ch, coin.Denom, amount, metadataByDenom[coin.Denom])
+		}
+	}
+
+	return denomsMetaErr == nil && totalSupplyErr == nil
+}
+
+// isDenomAllowed reports whether the denom passes the allow and deny regexes.
+func (c *DenomInfoCollector) isDenomAllowed(denom string) bool {
+	if c.allow != nil && !c.allow.MatchString(denom) {
+		return false
+	}
+	return c.deny == nil || !c.deny.MatchString(denom)
 }
 
 func (c *DenomInfoCollector) collectDenomMetadata(ch chan<- prometheus.Metric, resp *bankv1beta1.QueryDenomMetadataResponse, queryErr error) {
@@ -112,38 +295,57 @@ func (c *DenomInfoCollector) collectDenomMetadata(ch chan<- prometheus.Metric, r
 		return
 	}
 
-	metadata := resp.Metadata
+	if resp.Metadata != nil {
+		c.reportDenomMetadata(ch, resp.Metadata)
+	}
+}
 
-	if metadata != nil {
-		metric, err := prometheus.NewConstMetric(
-			c.denomMetaDesc,
-			prometheus.GaugeValue,
-			1, // Value is 1 to indicate presence/info
-			metadata.Symbol,
-			metadata.Base,
-			metadata.Name,
-			metadata.Display,
-		)
-		if err != nil {
-			slog.Error("Failed to create denom metadata metric", "symbol", metadata.Symbol, "base", metadata.Base, "error", err)
-		} else {
-			ch <- metric
-		}
+func (c *DenomInfoCollector) reportDenomMetadata(ch chan<- prometheus.Metric, metadata *bankv1beta1.Metadata) {
+	metric, err := prometheus.NewConstMetric(
+		c.denomMetaDesc,
+		prometheus.GaugeValue,
+		1, // Value is 1 to indicate presence/info
+		metadata.Symbol,
+		metadata.Base,
+		metadata.Name,
+		metadata.Display,
+	)
+	if err != nil {
+		slog.Error("Failed to create denom metadata metric", "symbol", metadata.Symbol, "base", metadata.Base, "error", err)
+	} else {
+		ch <- metric
 	}
 }
 
-func (c *DenomInfoCollector) collectTotalSupply(ch chan<- prometheus.Metric, resp *bankv1beta1.QuerySupplyOfResponse, queryErr error) {
+func (c *DenomInfoCollector) collectTotalSupply(ch chan<- prometheus.Metric, denom string, amount *big.Int, queryErr error, metaResp *bankv1beta1.QueryDenomMetadataResponse, metaErr error) {
 	if queryErr != nil {
 		collectors.ReportInvalidMetric(ch, c.totalSupplyDesc, queryErr)
+		collectors.ReportInvalidMetric(ch, c.totalAmountDesc, queryErr)
 		return
 	}
-	if resp == nil {
+	// Supply amounts can't be scaled without the metadata. Denoms without metadata are reported in base units.
+	scalable := metaErr == nil || status.Code(metaErr) == codes.NotFound
+	if !scalable {
+		collectors.ReportInvalidMetric(ch, c.totalAmountDesc, metaErr)
+	}
+	if !scalable {
+		c.reportTotalSupplyLabel(ch, denom, amount)
 		return
 	}
-	coin := resp.Amount
-	if coin == nil {
-		slog.Warn("Total supply response is nil")
-		collectors.ReportInvalidMetric(ch, c.totalSupplyDesc, status.Error(codes.Internal, "total supply response is nil"))
+	c.reportTotalSupply(ch, denom, amount, metaResp.GetMetadata())
+}
+
+// reportTotalSupply reports the total supply in display units, derived from the metadata, and as a label if enabled.
+// The supply is reported in base units if the metadata is nil.
+func (c *DenomInfoCollector) reportTotalSupply(ch chan<- prometheus.Metric, denom string, amount *big.Int, metadata *bankv1beta1.Metadata) {
+	c.reportTotalSupplyLabel(ch, denom, amount)
+
+	display, exponent := displayUnit(denom, metadata)
+	collectors.ReportGaugeMetric(ch, c.totalAmountDesc, toDisplayAmount(amount, exponent), denom, display)
+}
+
+func (c *DenomInfoCollector) reportTotalSupplyLabel(ch chan<- prometheus.Metric, denom string, amount *big.Int) {
+	if !c.amountLabels {
 		return
 	}
 
@@ -154,18 +356,25 @@ func (c *DenomInfoCollector) collectTotalSupply(ch chan<- prometheus.Metric, res
 		c.totalSupplyDesc,
 		prometheus.GaugeValue,
 		1, // Let the client handle the metadata.
-		coin.Denom,
-		coin.Amount,
+		denom,
+		amount.String(),
 	)
 	if err != nil {
-		slog.Error("Failed to create total supply metric", "denom", coin.Denom, "error", err)
+		slog.Error("Failed to create total supply metric", "denom", denom, "error", err)
 	} else {
 		ch <- metric
 	}
 }
 
 func init() {
-	RegisterCollectorFactory("denom_metadata", func(grpcClient *client.GRPCClient, extra ...interface{}) prometheus.Collector {
-		return NewDenomInfoCollector(grpcClient, "umfx")
+	RegisterCollectorFactory("denom_metadata", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := denomInfoOptions{amountOptions: defaultAmountOptions()}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid denom_metadata options, using configured denoms", "error", err)
+		}
+		if opts.AllDenoms {
+			return NewAllDenomsInfoCollector(grpcClient, opts.Allow, opts.Deny, opts.AmountLabels, cfg.Timeout)
+		}
+		return NewDenomInfoCollector(grpcClient, cfg.Denoms, opts.AmountLabels, cfg.Timeout)
 	})
 }
@@ -0,0 +1,126 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeDenomsBankServer serves the metadata and supply of every denom over two pages.
+type fakeDenomsBankServer struct {
+	bankv1beta1.UnimplementedQueryServer
+}
+
+// denomsPage returns the page of items requested by page, and the key of the next page, if any.
+func denomsPage[T any](page *queryv1beta1.PageRequest, first, second []T) ([]T, *queryv1beta1.PageResponse) {
+	if string(page.GetKey()) == "second" {
+		return second, &queryv1beta1.PageResponse{}
+	}
+	return first, &queryv1beta1.PageResponse{NextKey: []byte("second")}
+}
+
+func (fakeDenomsBankServer) DenomsMetadata(_ context.Context, req *bankv1beta1.QueryDenomsMetadataRequest) (*bankv1beta1.QueryDenomsMetadataResponse, error) {
+	metadatas, page := denomsPage(req.Pagination,
+		[]*bankv1beta1.Metadata{{
+			Base:       "umfx",
+			Display:    "mfx",
+			Symbol:     "MFX",
+			DenomUnits: []*bankv1beta1.DenomUnit{{Denom: "umfx"}, {Denom: "mfx", Exponent: 6}},
+		}},
+		[]*bankv1beta1.Metadata{
+			{Base: "factory/manifest1abc/uabc", Display: "factory/manifest1abc/uabc"},
+			{Base: "factory/manifest1abc/ubad", Display: "factory/manifest1abc/ubad"},
+		},
+	)
+	return &bankv1beta1.QueryDenomsMetadataResponse{Metadatas: metadatas, Pagination: page}, nil
+}
+
+func (fakeDenomsBankServer) TotalSupply(_ context.Context, req *bankv1beta1.QueryTotalSupplyRequest) (*bankv1beta1.QueryTotalSupplyResponse, error) {
+	supply, page := denomsPage(req.Pagination,
+		[]*basev1beta1.Coin{{Denom: "umfx", Amount: "3000000"}, {Denom: "upwr", Amount: "70"}},
+		[]*basev1beta1.Coin{
+			{Denom: "factory/manifest1abc/uabc", Amount: "5"},
+			{Denom: "factory/manifest1abc/ubad", Amount: "6"},
+			{Denom: "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", Amount: "7"},
+		},
+	)
+	return &bankv1beta1.QueryTotalSupplyResponse{Supply: supply, Pagination: page}, nil
+}
+
+func TestAllDenomsInfoCollector(t *testing.T) {
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		bankv1beta1.RegisterQueryServer(server, fakeDenomsBankServer{})
+		tmv1beta1.RegisterServiceServer(server, fakeNodeStatusServer{blockHeight: 42, blockTime: time.Unix(1_700_000_000, 0)})
+	})
+	got := gatherValues(t, NewAllDenomsInfoCollector(grpcClient, "^(u|factory/)", "ubad$", false, time.Second))
+
+	want := map[string]float64{
+		"manifest_tokenomics_denom_metadata{denom=umfx,display=mfx,name=,symbol=MFX}":                                         1,
+		"manifest_tokenomics_denom_metadata{denom=factory/manifest1abc/uabc,display=factory/manifest1abc/uabc,name=,symbol=}": 1,
+		"manifest_tokenomics_total_supply_amount{denom=umfx,display=mfx}":                                                     3,
+		"manifest_tokenomics_total_supply_amount{denom=upwr,display=upwr}":                                                    70,
+		"manifest_tokenomics_total_supply_amount{denom=factory/manifest1abc/uabc,display=factory/manifest1abc/uabc}":          5,
+		"manifest_tokenomics_denom_height":  42,
+		"manifest_tokenomics_denom_grpc_up": 1,
+	}
+	assertValues(t, got, want)
+	// The denied factory denom and the IBC denom, not allowed, are left out.
+	if len(got) != len(want) {
+		t.Errorf("series = %v, want only %v", got, want)
+	}
+}
+
+// fakePinnedBankServer records the block height requested by the metadata of the denom queries.
+type fakePinnedBankServer struct {
+	fakeMetadataServer
+
+	mu      sync.Mutex
+	heights []string
+}
+
+func (s *fakePinnedBankServer) record(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heights = append(s.heights, md.Get(blockHeightMetadataKey)...)
+}
+
+func (s *fakePinnedBankServer) DenomMetadata(ctx context.Context, req *bankv1beta1.QueryDenomMetadataRequest) (*bankv1beta1.QueryDenomMetadataResponse, error) {
+	s.record(ctx)
+	return s.fakeMetadataServer.DenomMetadata(ctx, req)
+}
+
+func (s *fakePinnedBankServer) SupplyOf(ctx context.Context, req *bankv1beta1.QuerySupplyOfRequest) (*bankv1beta1.QuerySupplyOfResponse, error) {
+	s.record(ctx)
+	return &bankv1beta1.QuerySupplyOfResponse{Amount: &basev1beta1.Coin{Denom: req.Denom, Amount: "3000000"}}, nil
+}
+
+func TestDenomInfoCollectorPinsHeight(t *testing.T) {
+	bank := &fakePinnedBankServer{}
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		bankv1beta1.RegisterQueryServer(server, bank)
+		tmv1beta1.RegisterServiceServer(server, fakeNodeStatusServer{blockHeight: 42, blockTime: time.Unix(1_700_000_000, 0)})
+	})
+	got := gatherValues(t, NewDenomInfoCollector(grpcClient, []string{"umfx"}, false, time.Second))
+
+	assertValues(t, got, map[string]float64{
+		"manifest_tokenomics_total_supply_amount{denom=umfx,display=mfx}": 3,
+		"manifest_tokenomics_denom_height":                                42,
+	})
+	// Both the DenomMetadata and SupplyOf queries are served at the latest height.
+	if !slices.Equal(bank.heights, []string{"42", "42"}) {
+		t.Errorf("%s metadata = %v, want [42 42]", blockHeightMetadataKey, bank.heights)
+	}
+}
@@ -0,0 +1,159 @@
+package manifestd
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math/big"
+	"strings"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// amountOptions holds the options shared by the collectors reporting token amounts.
+type amountOptions struct {
+	// AmountLabels also reports the exact amount in base units as a label of the deprecated legacy metrics.
+	// Such series change on every amount change, so they can be disabled to bound cardinality.
+	AmountLabels bool `mapstructure:"amount_labels"`
+}
+
+// defaultAmountOptions keeps the amount labels enabled for backward compatibility.
+func defaultAmountOptions() amountOptions {
+	return amountOptions{AmountLabels: true}
+}
+
+// displayUnit returns the display denom and its exponent from the denom metadata.
+// Amounts of denoms without metadata, or whose display unit is not listed, are reported in base units.
+func displayUnit(denom string, metadata *bankv1beta1.Metadata) (string, uint32) {
+	if metadata == nil || metadata.Display == "" {
+		return denom, 0
+	}
+	for _, unit := range metadata.DenomUnits {
+		if unit != nil && unit.Denom == metadata.Display {
+			return unit.Denom, unit.Exponent
+		}
+	}
+	return denom, 0
+}
+
+// queryDisplayUnit queries the denom metadata and returns the display denom and its exponent.
+// A denom without metadata is reported in base units.
+func queryDisplayUnit(ctx context.Context, bankQueryClient bankv1beta1.QueryClient, denom string) (string, uint32, error) {
+	resp, err := bankQueryClient.DenomMetadata(ctx, &bankv1beta1.QueryDenomMetadataRequest{Denom: denom})
+	if status.Code(err) == codes.NotFound {
+		return denom, 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	display, exponent := displayUnit(denom, resp.GetMetadata())
+	return display, exponent, nil
+}
+
+// querySupplyOf returns the total supply of a denom, in base units.
+// It is shared by the denom_metadata collector and the supply API, so that both report the same total supply.
+func querySupplyOf(ctx context.Context, bankQueryClient bankv1beta1.QueryClient, denom string) (*big.Int, error) {
+	resp, err := bankQueryClient.SupplyOf(ctx, &bankv1beta1.QuerySupplyOfRequest{Denom: denom})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetAmount() == nil {
+		return nil, status.Error(codes.Internal, "total supply response is nil")
+	}
+	return parseAmount(resp.Amount.Amount)
+}
+
+// displayUnitInfo holds the display unit of a denom.
+type displayUnitInfo struct {
+	display  string
+	exponent uint32
+}
+
+// queryDisplayUnits returns the display unit of every given denom.
+// Each metadata query is bounded by the given timeout, so that a slow query does not fail the following ones.
+func queryDisplayUnits(ctx context.Context, bankQueryClient bankv1beta1.QueryClient, denoms iter.Seq[string], timeout time.Duration) (map[string]displayUnitInfo, error) {
+	units := make(map[string]displayUnitInfo)
+	for denom := range denoms {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		display, exponent, err := queryDisplayUnit(callCtx, bankQueryClient, denom)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("denom %s: %w", denom, err)
+		}
+		units[denom] = displayUnitInfo{display: display, exponent: exponent}
+	}
+	return units, nil
+}
+
+// parseAmount parses an integer amount in base units.
+func parseAmount(amount string) (*big.Int, error) {
+	v, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "invalid coin amount: %s", amount)
+	}
+	return v, nil
+}
+
+// truncateDecCoins converts decimal coins to base units by denom, keeping only the integer part.
+func truncateDecCoins(coins []*basev1beta1.DecCoin) (map[string]*big.Int, error) {
+	amounts := make(map[string]*big.Int, len(coins))
+	for _, coin := range coins {
+		amount, err := parseLegacyDec(coin.Amount)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid coin amount %s%s", coin.Amount, coin.Denom)
+		}
+		if amounts[coin.Denom] == nil {
+			amounts[coin.Denom] = new(big.Int)
+		}
+		amounts[coin.Denom].Add(amounts[coin.Denom], amount.TruncateInt().BigInt())
+	}
+	return amounts, nil
+}
+
+// addCoins adds the amounts to the totals, by denom.
+func addCoins(totals, amounts map[string]*big.Int) {
+	for denom, amount := range amounts {
+		if totals[denom] == nil {
+			totals[denom] = new(big.Int)
+		}
+		totals[denom].Add(totals[denom], amount)
+	}
+}
+
+// zeroCoins returns a zero amount for every denom of the given amounts.
+func zeroCoins(amounts map[string]*big.Int) map[string]*big.Int {
+	zeros := make(map[string]*big.Int, len(amounts))
+	for denom := range amounts {
+		zeros[denom] = new(big.Int)
+	}
+	return zeros
+}
+
+// toDisplayAmount converts an amount in base units to display units.
+// The result is a float and may lose precision; the exact amount must be read from the base units.
+func toDisplayAmount(amount *big.Int, exponent uint32) float64 {
+	value := new(big.Float).SetInt(amount)
+	if exponent > 0 {
+		value.Quo(value, new(big.Float).SetInt(exponentScale(exponent)))
+	}
+	f, _ := value.Float64()
+	return f
+}
+
+// formatDisplayAmount formats an amount in base units as an exact decimal number in display units.
+func formatDisplayAmount(amount *big.Int, exponent uint32) string {
+	if exponent == 0 {
+		return amount.String()
+	}
+	formatted := new(big.Rat).SetFrac(amount, exponentScale(exponent)).FloatString(int(exponent))
+	return strings.TrimSuffix(strings.TrimRight(formatted, "0"), ".")
+}
+
+// exponentScale returns 10^exponent.
+func exponentScale(exponent uint32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
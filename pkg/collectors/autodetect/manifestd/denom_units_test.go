@@ -0,0 +1,42 @@
+package manifestd
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// slowMetadataServer answers every metadata query after a delay, without metadata.
+type slowMetadataServer struct {
+	bankv1beta1.UnimplementedQueryServer
+	delay time.Duration
+}
+
+func (s slowMetadataServer) DenomMetadata(context.Context, *bankv1beta1.QueryDenomMetadataRequest) (*bankv1beta1.QueryDenomMetadataResponse, error) {
+	time.Sleep(s.delay)
+	return nil, status.Error(codes.NotFound, "no metadata")
+}
+
+func TestQueryDisplayUnitsTimeoutPerDenom(t *testing.T) {
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		bankv1beta1.RegisterQueryServer(server, slowMetadataServer{delay: 40 * time.Millisecond})
+	})
+
+	// Every query fits in the timeout, but all of them together do not.
+	denoms := []string{"uone", "utwo", "uthree", "ufour"}
+	units, err := queryDisplayUnits(context.Background(), bankv1beta1.NewQueryClient(grpcClient.Conn), slices.Values(denoms), 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("queryDisplayUnits() error = %v", err)
+	}
+	for _, denom := range denoms {
+		if unit := units[denom]; unit.display != denom || unit.exponent != 0 {
+			t.Errorf("unit of %s = %+v, want the base unit", denom, unit)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"resty.dev/v3"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg"
+)
+
+// defaultAddressRefresh is the default minimum time between two requests to an HTTP address source.
+const defaultAddressRefresh = time.Minute
+
+// uncategorized is the category of the excluded addresses listed without one.
+const uncategorized = "uncategorized"
+
+// excludedAddress is an entry of an excluded address list.
+// Entries are either a plain address or an object with an optional label and category, e.g.
+//
+//   - manifest1...
+//   - address: manifest1...
+//     label: Treasury multisig
+//     category: treasury
+type excludedAddress struct {
+	Address  string `json:"address" yaml:"address" mapstructure:"address"`
+	Label    string `json:"label" yaml:"label" mapstructure:"label"`          // Human-readable name, optional
+	Category string `json:"category" yaml:"category" mapstructure:"category"` // Group of addresses, e.g. treasury or team-vesting, optional
+}
+
+// UnmarshalText decodes an entry given as a plain address.
+func (a *excludedAddress) UnmarshalText(text []byte) error {
+	*a = excludedAddress{Address: string(text)}
+	return nil
+}
+
+// UnmarshalJSON decodes an entry given either as a plain address or as an object.
+func (a *excludedAddress) UnmarshalJSON(data []byte) error {
+	var address string
+	if err := json.Unmarshal(data, &address); err == nil {
+		return a.UnmarshalText([]byte(address))
+	}
+	type plain excludedAddress
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// UnmarshalYAML decodes an entry given either as a plain address or as a mapping.
+func (a *excludedAddress) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return a.UnmarshalText([]byte(node.Value))
+	}
+	type plain excludedAddress
+	return node.Decode((*plain)(a))
+}
+
+// categoryLabel returns the category of the entry, or uncategorized if it has none.
+func (a excludedAddress) categoryLabel() string {
+	if a.Category == "" {
+		return uncategorized
+	}
+	return a.Category
+}
+
+// addressSourceConfig holds the configuration of an excluded address source, e.g.
+//
+//	sources:
+//	  - type: file
+//	    path: /etc/excluded-addresses.yaml
+//	  - type: inline
+//	    addresses:
+//	      - manifest1...
+//	      - address: manifest1...
+//	        category: treasury
+//	  - type: http
+//	    url: https://example.com/addresses.json
+//	    refresh: 5m
+type addressSourceConfig struct {
+	Type      string            `mapstructure:"type"`      // file, inline or http
+	Path      string            `mapstructure:"path"`      // File path, for file sources
+	URL       string            `mapstructure:"url"`       // Endpoint, for http sources
+	Addresses []excludedAddress `mapstructure:"addresses"` // Addresses, for inline sources
+	Refresh   time.Duration     `mapstructure:"refresh"`   // Minimum time between requests, for http sources
+}
+
+// addressSource provides a list of excluded addresses.
+// Sources that already provided a list keep returning it when they fail.
+type addressSource interface {
+	Addresses(ctx context.Context) ([]excludedAddress, error)
+	String() string
+}
+
+// newAddressSource creates the address source described by the configuration.
+func newAddressSource(cfg addressSourceConfig) (addressSource, error) {
+	switch cfg.Type {
+	case "inline":
+		for i, address := range cfg.Addresses {
+			if err := validateAddress(address.Address, bech32Prefix); err != nil {
+				return nil, fmt.Errorf("inline address source, entry %d: %w", i+1, err)
+			}
+		}
+		return &inlineAddressSource{addresses: cfg.Addresses}, nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, errors.New("file address source requires a path")
+		}
+		return &fileAddressSource{path: cfg.Path}, nil
+	case "http":
+		if cfg.URL == "" {
+			return nil, errors.New("http address source requires a url")
+		}
+		refresh := cfg.Refresh
+		if refresh <= 0 {
+			refresh = defaultAddressRefresh
+		}
+		return newHTTPAddressSource(cfg.URL, refresh), nil
+	default:
+		return nil, fmt.Errorf("unknown address source type %q, expected file, inline or http", cfg.Type)
+	}
+}
+
+// addressList is the union of several address sources.
+type addressList struct {
+	sources []addressSource
+}
+
+// Addresses returns the addresses of all sources, without duplicates.
+// The label and category of a duplicate address are taken from the first source that sets them.
+// It fails if any source has never provided a list.
+func (l *addressList) Addresses(ctx context.Context) ([]excludedAddress, error) {
+	index := make(map[string]int)
+	var addresses []excludedAddress
+	for _, source := range l.sources {
+		sourceAddresses, err := source.Addresses(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("address source %s: %w", source, err)
+		}
+		for _, address := range sourceAddresses {
+			i, ok := index[address.Address]
+			if !ok {
+				index[address.Address] = len(addresses)
+				addresses = append(addresses, address)
+				continue
+			}
+			if addresses[i].Label == "" {
+				addresses[i].Label = address.Label
+			}
+			if addresses[i].Category == "" {
+				addresses[i].Category = address.Category
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// inlineAddressSource provides addresses listed in the configuration file.
+type inlineAddressSource struct {
+	addresses []excludedAddress
+}
+
+func (s *inlineAddressSource) Addresses(context.Context) ([]excludedAddress, error) {
+	return s.addresses, nil
+}
+
+func (s *inlineAddressSource) String() string {
+	return "inline"
+}
+
+// fileAddressSource provides addresses read from a JSON, YAML or CSV file.
+// The file is read again whenever its modification time changes.
+type fileAddressSource struct {
+	path string
+
+	mu        sync.Mutex
+	modTime   time.Time
+	addresses []excludedAddress
+	loaded    bool
+}
+
+func (s *fileAddressSource) Addresses(context.Context) ([]excludedAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return s.lastKnownGood(err)
+	}
+	if s.loaded && info.ModTime().Equal(s.modTime) {
+		return s.addresses, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return s.lastKnownGood(err)
+	}
+	addresses, err := parseAddressList(data, filepath.Ext(s.path))
+	if err != nil {
+		return s.lastKnownGood(err)
+	}
+
+	slog.Info("Loaded excluded addresses", "source", s.String(), "count", len(addresses))
+	s.addresses = addresses
+	s.modTime = info.ModTime()
+	s.loaded = true
+	return addresses, nil
+}
+
+func (s *fileAddressSource) lastKnownGood(err error) ([]excludedAddress, error) {
+	if !s.loaded {
+		return nil, err
+	}
+	slog.Warn("Failed to reload excluded addresses, using the last known good list", "source", s.String(), "error", err)
+	return s.addresses, nil
+}
+
+func (s *fileAddressSource) String() string {
+	return "file:" + s.path
+}
+
+// httpAddressSource provides addresses fetched from an HTTP endpoint returning a JSON array.
+// The endpoint is requested at most once per refresh period, and the list is only transferred when its ETag changes.
+type httpAddressSource struct {
+	url         string
+	refresh     time.Duration
+	restyClient *resty.Client
+
+	mu          sync.Mutex
+	etag        string
+	lastAttempt time.Time
+	addresses   []excludedAddress
+	loaded      bool
+}
+
+func newHTTPAddressSource(url string, refresh time.Duration) *httpAddressSource {
+	return &httpAddressSource{
+		url:         url,
+		refresh:     refresh,
+		restyClient: resty.New().SetHeader("Accept", "application/json").SetTimeout(pkg.ClientTimeout).SetRetryCount(pkg.ClientRetry),
+	}
+}
+
+func (s *httpAddressSource) Addresses(ctx context.Context) ([]excludedAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded && time.Since(s.lastAttempt) < s.refresh {
+		return s.addresses, nil
+	}
+	s.lastAttempt = time.Now()
+
+	req := s.restyClient.R().SetContext(ctx)
+	if s.etag != "" {
+		req.SetHeader("If-None-Match", s.etag)
+	}
+	resp, err := req.Get(s.url)
+	if err != nil {
+		return s.lastKnownGood(err)
+	}
+	if resp.StatusCode() == http.StatusNotModified {
+		if !s.loaded {
+			// Nothing was requested conditionally, the body is not a list.
+			return nil, fmt.Errorf("request to %s returned %s without a previous list", s.url, resp.Status())
+		}
+		return s.addresses, nil
+	}
+	if resp.IsError() {
+		return s.lastKnownGood(fmt.Errorf("request to %s failed: %s", s.url, resp.Status()))
+	}
+
+	addresses, err := parseAddressList(resp.Bytes(), ".json")
+	if err != nil {
+		return s.lastKnownGood(err)
+	}
+
+	slog.Info("Fetched excluded addresses", "source", s.String(), "count", len(addresses))
+	s.addresses = addresses
+	s.etag = resp.Header().Get("ETag")
+	s.loaded = true
+	return addresses, nil
+}
+
+func (s *httpAddressSource) lastKnownGood(err error) ([]excludedAddress, error) {
+	if !s.loaded {
+		return nil, err
+	}
+	slog.Warn("Failed to fetch excluded addresses, using the last known good list", "source", s.String(), "error", err)
+	return s.addresses, nil
+}
+
+func (s *httpAddressSource) String() string {
+	return "http:" + s.url
+}
+
+// parseAddressList parses a list of addresses in the format given by a file extension.
+// JSON and YAML lists hold address strings or objects with an address, label and category.
+// CSV files hold the address, label and category columns, with an optional header. Only the address column is required.
+// The whole list is rejected if any address is not a valid account address.
+func parseAddressList(data []byte, ext string) ([]excludedAddress, error) {
+	var addresses []excludedAddress
+	switch strings.ToLower(ext) {
+	case ".json":
+		if err := json.Unmarshal(data, &addresses); err != nil {
+			return nil, fmt.Errorf("invalid JSON address list: %w", err)
+		}
+		if err := validateAddressList(addresses); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &addresses); err != nil {
+			return nil, fmt.Errorf("invalid YAML address list: %w", err)
+		}
+		if err := validateAddressList(addresses); err != nil {
+			return nil, err
+		}
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid CSV address list: %w", err)
+			}
+			address := excludedAddress{Address: strings.TrimSpace(record[0])}
+			if address.Address == "" || (len(addresses) == 0 && strings.EqualFold(address.Address, "address")) {
+				continue
+			}
+			if err := validateAddress(address.Address, bech32Prefix); err != nil {
+				line, _ := reader.FieldPos(0)
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if len(record) > 1 {
+				address.Label = strings.TrimSpace(record[1])
+			}
+			if len(record) > 2 {
+				address.Category = strings.TrimSpace(record[2])
+			}
+			addresses = append(addresses, address)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported address list format %q, expected .json, .yaml, .yml or .csv", ext)
+	}
+	return addresses, nil
+}
+
+// validateAddressList checks that all entries of a JSON or YAML address list are valid account addresses.
+func validateAddressList(addresses []excludedAddress) error {
+	for i, address := range addresses {
+		if err := validateAddress(address.Address, bech32Prefix); err != nil {
+			return fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// newAddressList creates the union of the configured sources.
+// The legacy endpoint, when set, is added as an HTTP source.
+func newAddressList(configs []addressSourceConfig, endpoint string) (*addressList, error) {
+	if endpoint != "" {
+		// Appending to the configuration of the caller could overwrite its backing array.
+		configs = append(slices.Clone(configs), addressSourceConfig{Type: "http", URL: endpoint})
+	}
+
+	list := &addressList{}
+	for _, cfg := range configs {
+		source, err := newAddressSource(cfg)
+		if err != nil {
+			return nil, err
+		}
+		list.sources = append(list.sources, source)
+	}
+	return list, nil
+}
+
+// The excluded address list outlives the collectors, so that the last known good list and HTTP caches
+// survive the re-detection of the manifestd process, as long as the configuration of the sources is unchanged.
+var (
+	sharedAddressesMu       sync.Mutex
+	sharedAddresses         *addressList
+	sharedAddressesConfigs  []addressSourceConfig
+	sharedAddressesEndpoint string
+)
+
+// sharedAddressList returns the address list built from the configuration, reusing the list of the previous
+// activation when its configuration is the same. Invalid configurations are not cached, so that they are
+// checked again at the next activation.
+func sharedAddressList(configs []addressSourceConfig, endpoint string) (*addressList, error) {
+	sharedAddressesMu.Lock()
+	defer sharedAddressesMu.Unlock()
+
+	if sharedAddresses != nil && sharedAddressesEndpoint == endpoint && reflect.DeepEqual(sharedAddressesConfigs, configs) {
+		return sharedAddresses, nil
+	}
+	list, err := newAddressList(configs, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	sharedAddresses, sharedAddressesConfigs, sharedAddressesEndpoint = list, slices.Clone(configs), endpoint
+	return list, nil
+}
@@ -0,0 +1,240 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseAddressList(t *testing.T) {
+	a, b, c := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2), testAddress(t, bech32Prefix, 3)
+	addresses := strings.NewReplacer("manifest1a", a, "manifest1b", b, "manifest1c", c)
+
+	tests := []struct {
+		name    string
+		data    string
+		ext     string
+		want    []excludedAddress
+		wantErr bool
+	}{
+		{
+			name: "JSON strings and objects",
+			data: `["manifest1a", {"address": "manifest1b", "label": "Treasury", "category": "treasury"}, {"address": "manifest1c"}]`,
+			ext:  ".json",
+			want: []excludedAddress{{Address: a}, {Address: b, Label: "Treasury", Category: "treasury"}, {Address: c}},
+		},
+		{
+			name: "YAML strings and mappings",
+			data: "- manifest1a\n- address: manifest1b\n  label: Treasury\n  category: treasury\n",
+			ext:  ".YML",
+			want: []excludedAddress{{Address: a}, {Address: b, Label: "Treasury", Category: "treasury"}},
+		},
+		{
+			name: "CSV with header and optional columns",
+			data: "address,label,category\nmanifest1a\nmanifest1b, Treasury ,treasury\n\nmanifest1c,Team\n",
+			ext:  ".csv",
+			want: []excludedAddress{{Address: a}, {Address: b, Label: "Treasury", Category: "treasury"}, {Address: c, Label: "Team"}},
+		},
+		{
+			name: "CSV without header",
+			data: "manifest1a,Treasury,treasury\nmanifest1b\n",
+			ext:  ".csv",
+			want: []excludedAddress{{Address: a, Label: "Treasury", Category: "treasury"}, {Address: b}},
+		},
+		{name: "invalid JSON", data: `{"address": "manifest1a"}`, ext: ".json", wantErr: true},
+		{name: "invalid CSV", data: "manifest1a,\"unterminated\n", ext: ".csv", wantErr: true},
+		{name: "unsupported format", data: "manifest1a", ext: ".txt", wantErr: true},
+		{name: "invalid JSON address", data: `["manifest1a", "manifest1invalid"]`, ext: ".json", wantErr: true},
+		{name: "YAML address with another prefix", data: "- manifest1a\n- " + testAddress(t, consensusPrefix, 1) + "\n", ext: ".yaml", wantErr: true},
+		{name: "invalid CSV address", data: "address,label\nmanifest1a,Treasury\nmanifest1b-typo,Team\n", ext: ".csv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAddressList([]byte(addresses.Replace(tt.data)), tt.ext)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseAddressList() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: parseAddressList() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileAddressSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addresses.yaml")
+	write := func(data string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	a, b := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2)
+
+	// A source that never loaded a list fails.
+	source := &fileAddressSource{path: path}
+	if _, err := source.Addresses(context.Background()); err == nil {
+		t.Fatal("Addresses() of a missing file succeeded")
+	}
+
+	write("- "+a+"\n", start)
+	got, err := source.Addresses(context.Background())
+	if err != nil || !slices.Equal(got, []excludedAddress{{Address: a}}) {
+		t.Fatalf("Addresses() = %+v, %v, want [%s]", got, err, a)
+	}
+
+	write("- "+b+"\n", start.Add(time.Minute))
+	got, err = source.Addresses(context.Background())
+	if err != nil || !slices.Equal(got, []excludedAddress{{Address: b}}) {
+		t.Fatalf("Addresses() after an update = %+v, %v, want [%s]", got, err, b)
+	}
+
+	// Invalid or missing files fall back to the last known good list.
+	write("address: [", start.Add(2*time.Minute))
+	got, err = source.Addresses(context.Background())
+	if err != nil || !slices.Equal(got, []excludedAddress{{Address: b}}) {
+		t.Errorf("Addresses() of an invalid file = %+v, %v, want the last known good list", got, err)
+	}
+	write("- "+a+"\n- manifest1invalid\n", start.Add(3*time.Minute))
+	got, err = source.Addresses(context.Background())
+	if err != nil || !slices.Equal(got, []excludedAddress{{Address: b}}) {
+		t.Errorf("Addresses() of a file with an invalid address = %+v, %v, want the last known good list", got, err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err = source.Addresses(context.Background())
+	if err != nil || !slices.Equal(got, []excludedAddress{{Address: b}}) {
+		t.Errorf("Addresses() of a removed file = %+v, %v, want the last known good list", got, err)
+	}
+}
+
+// addressServer serves an address list with an ETag, answering conditional requests with 304 Not Modified.
+type addressServer struct {
+	list     []byte // JSON address list
+	requests atomic.Int32
+	status   atomic.Int32 // Status forced on every request, 0 to serve the list
+}
+
+func (s *addressServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests.Add(1)
+	if status := int(s.status.Load()); status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	if r.Header.Get("If-None-Match") == `"v1"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", `"v1"`)
+	_, _ = w.Write(s.list)
+}
+
+func TestHTTPAddressSource(t *testing.T) {
+	a, b := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2)
+	want := []excludedAddress{{Address: a}, {Address: b, Category: "treasury"}}
+	handler := &addressServer{list: []byte(fmt.Sprintf(`[%q, {"address": %q, "category": "treasury"}]`, a, b))}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := newHTTPAddressSource(server.URL, time.Hour)
+	got, err := source.Addresses(context.Background())
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("Addresses() = %+v, %v, want %+v", got, err, want)
+	}
+
+	// Within the refresh period, the endpoint is not requested.
+	if got, err = source.Addresses(context.Background()); err != nil || !slices.Equal(got, want) {
+		t.Errorf("throttled Addresses() = %+v, %v, want %+v", got, err, want)
+	}
+	if requests := handler.requests.Load(); requests != 1 {
+		t.Errorf("requests within the refresh period = %d, want 1", requests)
+	}
+
+	// Once the period is over, the list is reused when the ETag did not change.
+	source.refresh = 0
+	if got, err = source.Addresses(context.Background()); err != nil || !slices.Equal(got, want) {
+		t.Errorf("Addresses() after 304 = %+v, %v, want %+v", got, err, want)
+	}
+	if requests := handler.requests.Load(); requests != 2 {
+		t.Errorf("requests after the refresh period = %d, want 2", requests)
+	}
+
+	// Failed requests fall back to the last known good list.
+	handler.status.Store(http.StatusNotFound)
+	if got, err = source.Addresses(context.Background()); err != nil || !slices.Equal(got, want) {
+		t.Errorf("Addresses() of a failing endpoint = %+v, %v, want the last known good list", got, err)
+	}
+}
+
+func TestHTTPAddressSourceWithoutList(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{"not found", http.StatusNotFound},
+		{"not modified", http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		handler := &addressServer{}
+		handler.status.Store(int32(tt.status))
+		server := httptest.NewServer(handler)
+
+		source := newHTTPAddressSource(server.URL, time.Hour)
+		if got, err := source.Addresses(context.Background()); err == nil {
+			t.Errorf("%s: Addresses() = %+v, want an error", tt.name, got)
+		}
+		server.Close()
+	}
+}
+
+func TestSharedAddressList(t *testing.T) {
+	t.Cleanup(func() { sharedAddresses, sharedAddressesConfigs, sharedAddressesEndpoint = nil, nil, "" })
+	inline := []addressSourceConfig{{Type: "inline", Addresses: []excludedAddress{{Address: testAddress(t, bech32Prefix, 1)}}}}
+
+	// Invalid configurations are reported at every activation, until they are fixed.
+	for range 2 {
+		if _, err := sharedAddressList([]addressSourceConfig{{Type: "file"}}, ""); err == nil {
+			t.Fatal("sharedAddressList() of a file source without a path succeeded")
+		}
+	}
+	invalid := []addressSourceConfig{{Type: "inline", Addresses: []excludedAddress{{Address: "manifest1invalid"}}}}
+	if _, err := sharedAddressList(invalid, ""); err == nil {
+		t.Fatal("sharedAddressList() of an invalid inline address succeeded")
+	}
+
+	// The legacy endpoint is added without writing into the backing array of the configuration.
+	backing := append(make([]addressSourceConfig, 0, 2), inline...)
+	if _, err := newAddressList(backing, "http://127.0.0.1:1/addresses"); err != nil {
+		t.Fatal(err)
+	}
+	if extra := backing[:2][1]; extra.Type != "" {
+		t.Errorf("newAddressList() wrote %+v into the configuration", extra)
+	}
+
+	first, err := sharedAddressList(inline, "")
+	if err != nil {
+		t.Fatalf("sharedAddressList() error = %v", err)
+	}
+	if again, err := sharedAddressList(inline, ""); err != nil || again != first {
+		t.Errorf("sharedAddressList() of the same configuration = %p, %v, want the previous list %p", again, err, first)
+	}
+	if changed, err := sharedAddressList(inline, "http://127.0.0.1:1/addresses"); err != nil || changed == first || len(changed.sources) != 2 {
+		t.Errorf("sharedAddressList() of a changed configuration = %+v, %v, want a new list of 2 sources", changed, err)
+	}
+}
@@ -8,64 +8,199 @@ import (
 	"fmt"
 	"log/slog"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
 	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"resty.dev/v3"
 
-	"github.com/manifest-network/manifest-node-exporter/pkg"
 	"github.com/manifest-network/manifest-node-exporter/pkg/client"
 	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
-	"github.com/manifest-network/manifest-node-exporter/pkg/utils"
 )
 
+const (
+	defaultBalanceConcurrency  = 16                     // Default maximum number of concurrent balance queries
+	defaultBalanceRetries      = 3                      // Default number of retries of a failed balance query
+	defaultBalanceRetryBackoff = 500 * time.Millisecond // Default delay before the first retry, doubled after each retry
+	defaultMaxMissingRatio     = 1.0                    // Default maximum ratio of missing addresses, under the partial policy
+)
+
+// Policies applied when the balance of some excluded addresses cannot be queried.
+const (
+	failClosedPolicy = "fail_closed" // The excluded supply is invalid
+	partialPolicy    = "partial"     // The excluded supply is the sum of the available balances
+)
+
+// balanceQueryOptions holds the options of the balance queries of the excluded addresses.
+type balanceQueryOptions struct {
+	Concurrency     int           `mapstructure:"concurrency"`       // Maximum number of concurrent queries
+	Retries         int           `mapstructure:"retries"`           // Number of retries of a failed query
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`     // Delay before the first retry, doubled after each retry
+	Policy          string        `mapstructure:"policy"`            // fail_closed or partial
+	MaxMissingRatio float64       `mapstructure:"max_missing_ratio"` // Maximum ratio of missing addresses, under the partial policy
+}
+
+// excludedSupplyOptions holds the collector-specific options of the excluded supply collector.
+type excludedSupplyOptions struct {
+	amountOptions       `mapstructure:",squash"`
+	balanceQueryOptions `mapstructure:",squash"`
+	Sources             []addressSourceConfig `mapstructure:"sources"` // Sources of the excluded addresses, in addition to --addrs-endpoint
+	vestingOptions      `mapstructure:",squash"`
+}
+
+// vestingOptions holds the options of the exclusion of the tokens locked in vesting accounts.
+type vestingOptions struct {
+	Enabled bool          `mapstructure:"vesting_accounts"` // Exclude the tokens still locked in vesting accounts
+	Refresh time.Duration `mapstructure:"vesting_refresh"`  // Minimum time between two enumerations of the vesting accounts
+}
+
+// excludedBalance is the balance of an excluded address, in base units.
+type excludedBalance struct {
+	excludedAddress
+	amount *big.Int
+}
+
+// excludedSupplyResult holds the excluded supply and the balances it was computed from, in base units.
+type excludedSupplyResult struct {
+	total           *big.Int
+	balances        []excludedBalance // Balances of the addresses that could be queried
+	missing         int               // Number of addresses whose balance could not be queried
+	vestingLocked   *big.Int          // Tokens locked in vesting accounts, nil unless vesting accounts are excluded
+	vestingAccounts map[string]int    // Number of vesting accounts by type
+}
+
 type ExcludedSupplyCollector struct {
 	grpcClient         *client.GRPCClient
-	addrsEndpoint      string
-	restyClient        *resty.Client
-	excludedSupplyDesc *prometheus.Desc
+	addresses          *addressList
+	excludedSupplyDesc *prometheus.Desc // Excluded supply, as a label
+	excludedAmountDesc *prometheus.Desc // Excluded supply, in display units
+	addressAmountDesc  *prometheus.Desc // Balance of each excluded address, in display units
+	categoryAmountDesc *prometheus.Desc // Excluded supply of each category, in display units
+	missingDesc        *prometheus.Desc // Addresses missing from the excluded supply
+	heightDesc         *prometheus.Desc // Block height of the balance queries
+	vestingLockedDesc  *prometheus.Desc // Tokens locked in vesting accounts, in display units
+	vestingCountDesc   *prometheus.Desc // Vesting accounts by type
 	upDesc             *prometheus.Desc
 	denom              string
+	amountLabels       bool
+	vesting            *vestingAccountCache // Vesting accounts, nil unless they are excluded
+	queryOptions       balanceQueryOptions
+	timeout            time.Duration
 	initialError       error
 }
 
-func NewExcludedSupplyCollector(client *client.GRPCClient, endpoint, denom string) *ExcludedSupplyCollector {
+// NewExcludedSupplyCollector creates a new ExcludedSupplyCollector.
+// The excluded supply is the sum of the balances of the addresses provided by the address list.
+// The balances are also reported per address and per category of the address list.
+// If vesting is enabled, the tokens still locked in the vesting accounts of the chain are also excluded,
+// except for the accounts of the address list whose balances are already excluded.
+// The vesting accounts are enumerated at most once per refresh period of the vesting options.
+// All balances of a collection are queried at the same block height.
+// Failed balance queries are retried, and the policy of the query options decides whether addresses whose balance
+// still cannot be queried invalidate the excluded supply or are left out of it.
+func NewExcludedSupplyCollector(client *client.GRPCClient, addresses *addressList, denom string, amountLabels bool, vesting vestingOptions, queryOptions balanceQueryOptions, timeout time.Duration) *ExcludedSupplyCollector {
 	var initialError error
 	if client == nil {
 		initialError = status.Error(codes.Internal, "gRPC client is nil")
 	} else if client.Conn == nil {
 		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	} else if addresses == nil || (len(addresses.sources) == 0 && !vesting.Enabled) {
+		initialError = status.Error(codes.InvalidArgument, "no excluded address source, set --addrs-endpoint, the account_balance sources or vesting_accounts")
+	} else if queryOptions.Policy != failClosedPolicy && queryOptions.Policy != partialPolicy {
+		initialError = status.Errorf(codes.InvalidArgument, "unknown policy %q, expected %s or %s", queryOptions.Policy, failClosedPolicy, partialPolicy)
+	} else if queryOptions.Concurrency <= 0 || queryOptions.Retries < 0 || queryOptions.RetryBackoff < 0 {
+		initialError = status.Error(codes.InvalidArgument, "concurrency must be positive, retries and retry_backoff must not be negative")
+	} else if queryOptions.MaxMissingRatio < 0 || queryOptions.MaxMissingRatio > 1 {
+		initialError = status.Errorf(codes.InvalidArgument, "max_missing_ratio must be between 0 and 1, got %v", queryOptions.MaxMissingRatio)
+	}
+
+	var vestingCache *vestingAccountCache
+	if vesting.Enabled {
+		vestingCache = newVestingAccountCache(vesting.Refresh)
 	}
 
 	return &ExcludedSupplyCollector{
-		grpcClient:    client,
-		addrsEndpoint: endpoint,
-		restyClient:   resty.New().SetHeader("Accept", "application/json").SetTimeout(pkg.ClientTimeout).SetRetryCount(pkg.ClientRetry),
-		initialError:  initialError,
-		denom:         denom,
+		grpcClient:   client,
+		addresses:    addresses,
+		initialError: initialError,
+		denom:        denom,
+		amountLabels: amountLabels,
+		vesting:      vestingCache,
+		queryOptions: queryOptions,
+		timeout:      timeout,
 		excludedSupplyDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "excluded_supply"),
 			"Token supply to exclude from total supply to obtain circulating supply",
 			[]string{"excluded_supply", "denom"},
 			prometheus.Labels{"source": "grpc"},
 		),
+		excludedAmountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "excluded_supply_amount"),
+			"Token supply to exclude from total supply to obtain circulating supply, in display units",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		addressAmountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "excluded_address_amount"),
+			"Balance of an excluded address, in display units",
+			[]string{"address", "label", "category", "denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		categoryAmountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "excluded_category_amount"),
+			"Token supply excluded by the addresses of a category, in display units",
+			[]string{"category", "denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		missingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "excluded_missing_addresses"),
+			"Number of excluded addresses whose balance could not be queried and is missing from the excluded supply",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		heightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "excluded_supply_height"),
+			"Block height at which the excluded supply was queried",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		vestingLockedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "excluded_vesting_locked_amount"),
+			"Tokens still locked in vesting accounts, excluded from the circulating supply, in display units",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		vestingCountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "vesting_accounts"),
+			"Number of vesting accounts holding the denom, by type",
+			[]string{"type"},
+			prometheus.Labels{"source": "grpc"},
+		),
 		upDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "balance_grpc_up"),
 			"Whether the gRPC queries succeeded.",
 			nil,
-			prometheus.Labels{"source": "grpc", "query": "Balance"},
+			prometheus.Labels{"source": "grpc", "query": "GetLatestBlock, Balance, Accounts, DenomMetadata"},
 		),
 	}
 }
 
 func (c *ExcludedSupplyCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.excludedSupplyDesc
+	ch <- c.excludedAmountDesc
+	ch <- c.addressAmountDesc
+	ch <- c.categoryAmountDesc
+	ch <- c.missingDesc
+	ch <- c.heightDesc
+	ch <- c.vestingLockedDesc
+	ch <- c.vestingCountDesc
 	ch <- c.upDesc
 }
 
@@ -73,71 +208,266 @@ func (c *ExcludedSupplyCollector) Collect(ch chan<- prometheus.Metric) {
 	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
 		collectors.ReportUpMetric(ch, c.upDesc, 0)
 		collectors.ReportInvalidMetric(ch, c.excludedSupplyDesc, err)
+		collectors.ReportInvalidMetric(ch, c.excludedAmountDesc, err)
 		return
 	}
 
-	var addrs []string
-	if err := utils.DoJSONRequest(c.restyClient, c.addrsEndpoint, &addrs); err != nil {
-		slog.Error("Failed to fetch addresses", "endpoint", c.addrsEndpoint, "error", err)
+	ctx, latest, err := pinLatestHeight(c.grpcClient.Ctx, tmv1beta1.NewServiceClient(c.grpcClient.Conn), c.timeout)
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetLatestBlock", "error", err)
 		collectors.ReportUpMetric(ch, c.upDesc, 0)
 		collectors.ReportInvalidMetric(ch, c.excludedSupplyDesc, err)
+		collectors.ReportInvalidMetric(ch, c.excludedAmountDesc, err)
 		return
 	}
 
-	const rpcTimeout = 2 * time.Second
-	eg, egCtx := errgroup.WithContext(c.grpcClient.Ctx)
-	results := make(chan *big.Int, len(addrs))
+	result, err := c.excludedSupply(ctx, latest.Time)
+	if err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.excludedSupplyDesc, err)
+		collectors.ReportInvalidMetric(ch, c.excludedAmountDesc, err)
+		return
+	}
+
+	if c.amountLabels {
+		m, err := prometheus.NewConstMetric(c.excludedSupplyDesc, prometheus.GaugeValue, 1, result.total.String(), c.denom)
+		if err != nil {
+			slog.Error("Failed to create excluded supply metric", "error", err)
+		} else {
+			ch <- m
+		}
+	}
+
+	metaCtx, metaCancel := context.WithTimeout(ctx, c.timeout)
+	defer metaCancel()
+
+	display, exponent, metaErr := queryDisplayUnit(metaCtx, bankv1beta1.NewQueryClient(c.grpcClient.Conn), c.denom)
+	if metaErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "denom", c.denom, "error", metaErr)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.excludedAmountDesc, metaErr)
+		return
+	}
+
+	collectors.ReportUpMetric(ch, c.upDesc, 1)
+	collectors.ReportGaugeMetric(ch, c.excludedAmountDesc, toDisplayAmount(result.total, exponent), c.denom, display)
+	collectors.ReportGaugeMetric(ch, c.missingDesc, float64(result.missing))
+	collectors.ReportGaugeMetric(ch, c.heightDesc, float64(latest.Height))
+	if result.vestingLocked != nil {
+		collectors.ReportGaugeMetric(ch, c.vestingLockedDesc, toDisplayAmount(result.vestingLocked, exponent), c.denom, display)
+		for _, kind := range []string{continuousVesting, delayedVesting, periodicVesting, permanentLocked} {
+			collectors.ReportGaugeMetric(ch, c.vestingCountDesc, float64(result.vestingAccounts[kind]), kind)
+		}
+	}
+
+	categories := make(map[string]*big.Int)
+	for _, balance := range result.balances {
+		collectors.ReportGaugeMetric(ch, c.addressAmountDesc, toDisplayAmount(balance.amount, exponent), balance.Address, balance.Label, balance.categoryLabel(), c.denom, display)
+
+		category := balance.categoryLabel()
+		if categories[category] == nil {
+			categories[category] = new(big.Int)
+		}
+		categories[category].Add(categories[category], balance.amount)
+	}
+	for category, amount := range categories {
+		collectors.ReportGaugeMetric(ch, c.categoryAmountDesc, toDisplayAmount(amount, exponent), category, c.denom, display)
+	}
+}
+
+// excludedSupply fetches the excluded addresses and returns the sum of their balances, in base units.
+// With the partial policy, the addresses whose balance cannot be queried are counted as missing instead of failing,
+// as long as some balances were queried and the ratio of missing addresses does not exceed the configured maximum.
+// If vesting accounts are excluded, the tokens still locked at the block time are added to the sum.
+func (c *ExcludedSupplyCollector) excludedSupply(ctx context.Context, blockTime time.Time) (*excludedSupplyResult, error) {
+	addrs, err := c.addresses.Addresses(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch addresses", "error", err)
+		return nil, err
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(c.queryOptions.Concurrency)
+	amounts := make([]*big.Int, len(addrs))
+	var missing atomic.Int64
 
 	bankClient := bankv1beta1.NewQueryClient(c.grpcClient.Conn)
-	for _, addr := range addrs {
-		addr := addr
+	for i, entry := range addrs {
 		eg.Go(func() error {
-			callCtx, cancel := context.WithTimeout(egCtx, rpcTimeout)
-			defer cancel()
-
-			resp, err := bankClient.Balance(callCtx, &bankv1beta1.QueryBalanceRequest{
-				Address: addr,
-				Denom:   c.denom,
-			})
+			amount, err := c.queryBalance(egCtx, bankClient, entry.Address)
 			if err != nil {
-				slog.Error("Failed to query balance", "address", addr, "error", err)
-				return err
-			}
-			if v, ok := new(big.Int).SetString(resp.Balance.Amount, 10); ok {
-				results <- v
-			} else {
-				return fmt.Errorf("invalid coin amount for address %s: %s", addr, resp.Balance.Amount)
+				slog.Error("Failed to query balance", "address", entry.Address, "error", err)
+				if c.queryOptions.Policy == failClosedPolicy {
+					return err
+				}
+				missing.Add(1)
+				return nil
 			}
-
+			amounts[i] = amount
 			return nil
 		})
 	}
 
 	if err := eg.Wait(); err != nil {
-		collectors.ReportUpMetric(ch, c.upDesc, 0)
-		collectors.ReportInvalidMetric(ch, c.excludedSupplyDesc, err)
-		close(results)
-		return
+		return nil, err
 	}
-	close(results)
 
-	total := new(big.Int)
-	for v := range results {
-		total.Add(total, v)
+	result := &excludedSupplyResult{total: new(big.Int), missing: int(missing.Load())}
+	for i, amount := range amounts {
+		if amount == nil {
+			continue
+		}
+		result.total.Add(result.total, amount)
+		result.balances = append(result.balances, excludedBalance{excludedAddress: addrs[i], amount: amount})
+	}
+	if result.missing > 0 {
+		slog.Warn("Excluded supply is missing addresses", "missing", result.missing, "total", len(addrs))
+		// A sum without any balance, or missing too many of them, would grossly understate the excluded supply.
+		if result.missing == len(addrs) || float64(result.missing) > c.queryOptions.MaxMissingRatio*float64(len(addrs)) {
+			return nil, status.Errorf(codes.Unavailable, "balance of %d of %d excluded addresses could not be queried", result.missing, len(addrs))
+		}
 	}
 
-	collectors.ReportUpMetric(ch, c.upDesc, 1)
-	m, err := prometheus.NewConstMetric(c.excludedSupplyDesc, prometheus.GaugeValue, 1, total.String(), c.denom)
+	if c.vesting != nil {
+		if err := c.addVestingLocked(ctx, result, blockTime); err != nil {
+			slog.Error("Failed to query via gRPC", "query", "Accounts", "error", err)
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// addVestingLocked adds the tokens still locked in vesting accounts at the block time to the excluded supply.
+// Vesting accounts whose balance was counted from the address list are skipped, as their balances are already excluded.
+// Listed addresses whose balance could not be queried still have their locked tokens excluded.
+func (c *ExcludedSupplyCollector) addVestingLocked(ctx context.Context, result *excludedSupplyResult, blockTime time.Time) error {
+	accounts, err := c.vesting.Accounts(ctx, authv1beta1.NewQueryClient(c.grpcClient.Conn), c.denom, c.timeout)
 	if err != nil {
-		slog.Error("Failed to create excluded supply metric", "error", err)
-	} else {
-		ch <- m
+		return err
+	}
+
+	counted := make(map[string]struct{}, len(result.balances))
+	for _, balance := range result.balances {
+		counted[balance.Address] = struct{}{}
+	}
+
+	result.vestingLocked = new(big.Int)
+	result.vestingAccounts = make(map[string]int)
+	for _, account := range accounts {
+		result.vestingAccounts[account.Kind]++
+		if _, ok := counted[account.Address]; ok {
+			continue
+		}
+		result.vestingLocked.Add(result.vestingLocked, account.locked(blockTime))
 	}
+	result.total.Add(result.total, result.vestingLocked)
+	return nil
+}
+
+// queryBalance returns the balance of an address, in base units.
+// Queries failing with a transient error are retried with an exponential backoff.
+func (c *ExcludedSupplyCollector) queryBalance(ctx context.Context, bankClient bankv1beta1.QueryClient, address string) (*big.Int, error) {
+	backoff := c.queryOptions.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		resp, err := bankClient.Balance(callCtx, &bankv1beta1.QueryBalanceRequest{
+			Address: address,
+			Denom:   c.denom,
+		})
+		cancel()
+		if err == nil {
+			v, ok := new(big.Int).SetString(resp.GetBalance().GetAmount(), 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid coin amount for address %s: %s", address, resp.GetBalance().GetAmount())
+			}
+			return v, nil
+		}
+		if attempt >= c.queryOptions.Retries || !isTransientError(err) {
+			return nil, err
+		}
+
+		slog.Debug("Retrying balance query", "address", address, "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientError reports whether a failed gRPC query may succeed when retried,
+// e.g. when the node is rate limiting or temporarily unavailable.
+func isTransientError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Supply implements the supplySource interface used by the supply API.
+// The circulating supply is the total supply minus the excluded supply, both queried at the same block height.
+// With the partial policy, the addresses left out of the excluded supply are reported in the snapshot.
+func (c *ExcludedSupplyCollector) Supply(ctx context.Context) (*SupplySnapshot, error) {
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		return nil, err
+	}
+
+	ctx, latest, err := pinLatestHeight(ctx, tmv1beta1.NewServiceClient(c.grpcClient.Conn), c.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := c.excludedSupply(ctx, latest.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	bankClient := bankv1beta1.NewQueryClient(c.grpcClient.Conn)
+	total, err := querySupplyOf(queryCtx, bankClient, c.denom)
+	if err != nil {
+		return nil, err
+	}
+	display, exponent, err := queryDisplayUnit(queryCtx, bankClient, c.denom)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := newSupplySnapshot(c.denom, display, exponent, latest.Height, total, excluded.total)
+	snapshot.MissingAddresses = excluded.missing
+	return snapshot, nil
 }
 
 func init() {
-	RegisterCollectorFactory("account_balance", func(grpcClient *client.GRPCClient, extra ...interface{}) prometheus.Collector {
-		endpoint := viper.GetString("addrs-endpoint")
-		return NewExcludedSupplyCollector(grpcClient, endpoint, "umfx")
+	RegisterCollectorFactory("account_balance", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := excludedSupplyOptions{
+			amountOptions: defaultAmountOptions(),
+			balanceQueryOptions: balanceQueryOptions{
+				Concurrency:     defaultBalanceConcurrency,
+				Retries:         defaultBalanceRetries,
+				RetryBackoff:    defaultBalanceRetryBackoff,
+				Policy:          failClosedPolicy,
+				MaxMissingRatio: defaultMaxMissingRatio,
+			},
+		}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid account_balance options", "error", err)
+		}
+		addresses, err := sharedAddressList(opts.Sources, viper.GetString("addrs-endpoint"))
+		collector := NewExcludedSupplyCollector(grpcClient, addresses, cfg.Denom(), opts.AmountLabels, opts.vestingOptions, opts.balanceQueryOptions, cfg.Timeout)
+		if err != nil {
+			slog.Error("Invalid excluded address sources", "error", err)
+			// Report the invalid configuration rather than a missing address source.
+			collector.initialError = status.Errorf(codes.InvalidArgument, "invalid excluded address sources: %v", err)
+		}
+		if grpcClient != nil {
+			setSupplySource(grpcClient.Ctx, collector)
+		}
+		return collector
 	})
 }
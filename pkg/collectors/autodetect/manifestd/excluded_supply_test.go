@@ -0,0 +1,301 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	vestingv1beta1 "cosmossdk.io/api/cosmos/vesting/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeSupplyNode serves the bank and auth queries used by the excluded supply collector.
+type fakeSupplyNode struct {
+	balances  map[string]string // Balances by address, a missing address fails the query
+	transient map[string]int    // Number of transient failures of an address before its balance is served
+	vesting   []*anypb.Any
+	supply    string // Total supply
+
+	mu             sync.Mutex
+	attempts       map[string]int // Balance queries by address
+	accountQueries int            // Account enumerations
+}
+
+// enumerations returns the number of account enumerations served.
+func (n *fakeSupplyNode) enumerations() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.accountQueries
+}
+
+type fakeBankServer struct {
+	bankv1beta1.UnimplementedQueryServer
+	*fakeSupplyNode
+}
+
+func (s fakeBankServer) Balance(_ context.Context, req *bankv1beta1.QueryBalanceRequest) (*bankv1beta1.QueryBalanceResponse, error) {
+	s.mu.Lock()
+	if s.attempts == nil {
+		s.attempts = make(map[string]int)
+	}
+	s.attempts[req.Address]++
+	attempt := s.attempts[req.Address]
+	s.mu.Unlock()
+	if attempt <= s.transient[req.Address] {
+		return nil, status.Error(codes.Unavailable, "rate limited")
+	}
+
+	amount, ok := s.balances[req.Address]
+	if !ok {
+		return nil, status.Error(codes.Internal, "balance unavailable")
+	}
+	return &bankv1beta1.QueryBalanceResponse{Balance: &basev1beta1.Coin{Denom: req.Denom, Amount: amount}}, nil
+}
+
+func (s fakeBankServer) SupplyOf(_ context.Context, req *bankv1beta1.QuerySupplyOfRequest) (*bankv1beta1.QuerySupplyOfResponse, error) {
+	return &bankv1beta1.QuerySupplyOfResponse{Amount: &basev1beta1.Coin{Denom: req.Denom, Amount: s.supply}}, nil
+}
+
+func (fakeBankServer) DenomMetadata(context.Context, *bankv1beta1.QueryDenomMetadataRequest) (*bankv1beta1.QueryDenomMetadataResponse, error) {
+	return nil, status.Error(codes.NotFound, "no metadata")
+}
+
+// fakeSupplyTendermintServer serves the latest block, at which the supply queries are pinned.
+type fakeSupplyTendermintServer struct {
+	tmv1beta1.UnimplementedServiceServer
+}
+
+func (fakeSupplyTendermintServer) GetLatestBlock(context.Context, *tmv1beta1.GetLatestBlockRequest) (*tmv1beta1.GetLatestBlockResponse, error) {
+	return &tmv1beta1.GetLatestBlockResponse{SdkBlock: &tmv1beta1.Block{Header: &tmv1beta1.Header{
+		Height: 42,
+		Time:   timestamppb.New(time.Unix(1_700_000_000, 0)),
+	}}}, nil
+}
+
+type fakeAuthServer struct {
+	authv1beta1.UnimplementedQueryServer
+	*fakeSupplyNode
+}
+
+func (s fakeAuthServer) Accounts(context.Context, *authv1beta1.QueryAccountsRequest) (*authv1beta1.QueryAccountsResponse, error) {
+	s.mu.Lock()
+	s.accountQueries++
+	s.mu.Unlock()
+	return &authv1beta1.QueryAccountsResponse{Accounts: s.vesting}, nil
+}
+
+func delayedVestingAccount(t *testing.T, address, amount string, end time.Time) *anypb.Any {
+	t.Helper()
+	account, err := anypb.New(&vestingv1beta1.DelayedVestingAccount{BaseVestingAccount: &vestingv1beta1.BaseVestingAccount{
+		BaseAccount:     &authv1beta1.BaseAccount{Address: address},
+		OriginalVesting: []*basev1beta1.Coin{{Denom: "umfx", Amount: amount}},
+		EndTime:         end.Unix(),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return account
+}
+
+func TestExcludedSupplyPartialVesting(t *testing.T) {
+	counted, failed, unlisted := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2), testAddress(t, bech32Prefix, 3)
+	blockTime := time.Unix(1_700_000_000, 0)
+	end := blockTime.Add(time.Hour)
+
+	node := &fakeSupplyNode{
+		balances: map[string]string{counted: "100"},
+		vesting: []*anypb.Any{
+			delayedVestingAccount(t, counted, "100", end),
+			delayedVestingAccount(t, failed, "40", end),
+			delayedVestingAccount(t, unlisted, "7", end),
+		},
+	}
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		bankv1beta1.RegisterQueryServer(server, fakeBankServer{fakeSupplyNode: node})
+		authv1beta1.RegisterQueryServer(server, fakeAuthServer{fakeSupplyNode: node})
+	})
+
+	addresses, err := newAddressList([]addressSourceConfig{{
+		Type:      "inline",
+		Addresses: []excludedAddress{{Address: counted}, {Address: failed}},
+	}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryOptions := balanceQueryOptions{Concurrency: 2, Retries: 0, RetryBackoff: time.Millisecond, Policy: partialPolicy, MaxMissingRatio: 1}
+	c := NewExcludedSupplyCollector(grpcClient, addresses, "umfx", false, vestingOptions{Enabled: true}, queryOptions, time.Second)
+	if c.initialError != nil {
+		t.Fatal(c.initialError)
+	}
+
+	result, err := c.excludedSupply(context.Background(), blockTime)
+	if err != nil {
+		t.Fatalf("excludedSupply() error = %v", err)
+	}
+	if result.missing != 1 {
+		t.Errorf("missing = %d, want 1", result.missing)
+	}
+	// The counted balance, plus the locked tokens of the failed and unlisted vesting accounts.
+	if got := result.vestingLocked.String(); got != "47" {
+		t.Errorf("vesting locked = %s, want 47", got)
+	}
+	if got := result.total.String(); got != "147" {
+		t.Errorf("total = %s, want 147", got)
+	}
+}
+
+func TestExcludedSupplyVestingCache(t *testing.T) {
+	account := testAddress(t, bech32Prefix, 1)
+	blockTime := time.Unix(1_700_000_000, 0)
+	node := &fakeSupplyNode{vesting: []*anypb.Any{delayedVestingAccount(t, account, "100", blockTime.Add(time.Hour))}}
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		authv1beta1.RegisterQueryServer(server, fakeAuthServer{fakeSupplyNode: node})
+	})
+	addresses, err := newAddressList(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryOptions := balanceQueryOptions{Concurrency: 2, Retries: 0, RetryBackoff: time.Millisecond, Policy: failClosedPolicy}
+	c := NewExcludedSupplyCollector(grpcClient, addresses, "umfx", false, vestingOptions{Enabled: true, Refresh: time.Hour}, queryOptions, time.Second)
+
+	// Within the refresh period, the locked amounts are computed from the cached accounts.
+	for at, want := range map[time.Time]string{blockTime: "100", blockTime.Add(2 * time.Hour): "0"} {
+		result, err := c.excludedSupply(context.Background(), at)
+		if err != nil {
+			t.Fatalf("excludedSupply() error = %v", err)
+		}
+		if got := result.vestingLocked.String(); got != want {
+			t.Errorf("vesting locked at %s = %s, want %s", at, got, want)
+		}
+	}
+	if queries := node.enumerations(); queries != 1 {
+		t.Errorf("account enumerations within the refresh period = %d, want 1", queries)
+	}
+
+	// Once the period is over, the accounts are enumerated again.
+	c.vesting.refresh = 0
+	if _, err := c.excludedSupply(context.Background(), blockTime); err != nil {
+		t.Fatalf("excludedSupply() error = %v", err)
+	}
+	if queries := node.enumerations(); queries != 2 {
+		t.Errorf("account enumerations after the refresh period = %d, want 2", queries)
+	}
+}
+
+// newFakeSupplyCollector returns an excluded supply collector of the given addresses, backed by node.
+func newFakeSupplyCollector(t *testing.T, node *fakeSupplyNode, addresses []string, queryOptions balanceQueryOptions) *ExcludedSupplyCollector {
+	t.Helper()
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		bankv1beta1.RegisterQueryServer(server, fakeBankServer{fakeSupplyNode: node})
+		authv1beta1.RegisterQueryServer(server, fakeAuthServer{fakeSupplyNode: node})
+		tmv1beta1.RegisterServiceServer(server, fakeSupplyTendermintServer{})
+	})
+
+	var entries []excludedAddress
+	for _, address := range addresses {
+		entries = append(entries, excludedAddress{Address: address})
+	}
+	list, err := newAddressList([]addressSourceConfig{{Type: "inline", Addresses: entries}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewExcludedSupplyCollector(grpcClient, list, "umfx", false, vestingOptions{}, queryOptions, time.Second)
+	if c.initialError != nil {
+		t.Fatal(c.initialError)
+	}
+	return c
+}
+
+func TestQueryBalanceRetries(t *testing.T) {
+	address := testAddress(t, bech32Prefix, 1)
+	tests := []struct {
+		name         string
+		transient    int
+		balance      bool
+		retries      int
+		wantErr      codes.Code
+		wantAttempts int
+	}{
+		{"retry succeeds", 2, true, 3, codes.OK, 3},
+		{"retries run out", 5, true, 2, codes.Unavailable, 3},
+		{"no retry of a permanent error", 0, false, 3, codes.Internal, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &fakeSupplyNode{transient: map[string]int{address: tt.transient}, balances: map[string]string{}}
+			if tt.balance {
+				node.balances[address] = "42"
+			}
+			c := newFakeSupplyCollector(t, node, []string{address},
+				balanceQueryOptions{Concurrency: 1, Retries: tt.retries, RetryBackoff: time.Millisecond, Policy: failClosedPolicy})
+
+			amount, err := c.queryBalance(context.Background(), bankv1beta1.NewQueryClient(c.grpcClient.Conn), address)
+			if status.Code(err) != tt.wantErr {
+				t.Fatalf("queryBalance() error = %v, want code %s", err, tt.wantErr)
+			}
+			if err == nil && amount.String() != "42" {
+				t.Errorf("queryBalance() = %s, want 42", amount)
+			}
+			if attempts := node.attempts[address]; attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestExcludedSupplyPolicy(t *testing.T) {
+	ok, flaky, failing := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2), testAddress(t, bech32Prefix, 3)
+	tests := []struct {
+		name            string
+		policy          string
+		addresses       []string
+		maxMissingRatio float64
+		wantErr         bool
+		wantMissing     int
+		wantTotal       string
+	}{
+		{name: "fail closed", policy: failClosedPolicy, addresses: []string{ok, flaky, failing}, wantErr: true},
+		{name: "partial", policy: partialPolicy, addresses: []string{ok, flaky, failing}, maxMissingRatio: 1, wantMissing: 1, wantTotal: "150"},
+		{name: "partial within ratio", policy: partialPolicy, addresses: []string{ok, flaky, failing}, maxMissingRatio: 0.5, wantMissing: 1, wantTotal: "150"},
+		{name: "partial above ratio", policy: partialPolicy, addresses: []string{ok, flaky, failing}, maxMissingRatio: 0.2, wantErr: true},
+		{name: "partial without any balance", policy: partialPolicy, addresses: []string{failing}, maxMissingRatio: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &fakeSupplyNode{
+				balances:  map[string]string{ok: "100", flaky: "50"},
+				transient: map[string]int{flaky: 1, failing: 10},
+			}
+			c := newFakeSupplyCollector(t, node, tt.addresses,
+				balanceQueryOptions{Concurrency: 2, Retries: 2, RetryBackoff: time.Millisecond, Policy: tt.policy, MaxMissingRatio: tt.maxMissingRatio})
+
+			result, err := c.excludedSupply(context.Background(), time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("excludedSupply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.missing != tt.wantMissing {
+				t.Errorf("missing = %d, want %d", result.missing, tt.wantMissing)
+			}
+			if got := result.total.String(); got != tt.wantTotal {
+				t.Errorf("total = %s, want %s", got, tt.wantTotal)
+			}
+		})
+	}
+}
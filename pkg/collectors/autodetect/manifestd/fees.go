@@ -6,12 +6,13 @@ package manifestd
 import (
 	"context"
 	"log/slog"
+	"maps"
 	"math/big"
 	"time"
 
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
 	distributionv1beta1 "cosmossdk.io/api/cosmos/distribution/v1beta1"
 	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
-	"cosmossdk.io/math"
 	"github.com/manifest-network/manifest-node-exporter/pkg/client"
 	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,15 +21,43 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// feesConcurrency is the maximum number of validators queried concurrently.
+const feesConcurrency = 8
+
+// feesOptions holds the collector-specific options of the fees collector.
+type feesOptions struct {
+	amountOptions `mapstructure:",squash"`
+	PerValidator  bool `mapstructure:"per_validator"` // Report the rewards and commission of every validator
+}
+
+// validatorRewards holds the outstanding rewards and commission of a validator, in base units by denom.
+type validatorRewards struct {
+	operatorAddress string
+	moniker         string
+	outstanding     map[string]*big.Int
+	commission      map[string]*big.Int
+}
+
 type FeesCollector struct {
-	grpcClient   *client.GRPCClient
-	feesDesc     *prometheus.Desc
-	upDesc       *prometheus.Desc
-	denom        string
-	initialError error
+	grpcClient               *client.GRPCClient
+	feesDesc                 *prometheus.Desc // Fees, as a label
+	feesAmountDesc           *prometheus.Desc // Fees, in display units
+	commissionAmountDesc     *prometheus.Desc // Validator commission, in display units
+	delegatorRewardsDesc     *prometheus.Desc // Delegator rewards, in display units
+	validatorOutstandingDesc *prometheus.Desc // Per-validator outstanding rewards, in display units
+	validatorCommissionDesc  *prometheus.Desc // Per-validator commission, in display units
+	upDesc                   *prometheus.Desc
+	denoms                   []string
+	amountLabels             bool
+	perValidator             bool
+	timeout                  time.Duration
+	initialError             error
 }
 
-func NewFeesCollector(client *client.GRPCClient, denom string) *FeesCollector {
+// NewFeesCollector creates a new FeesCollector.
+// Fees of every denom held by validators are reported. The given denoms are always reported, even when no validator holds them.
+// Per-validator series are only reported if perValidator is set.
+func NewFeesCollector(client *client.GRPCClient, denoms []string, amountLabels, perValidator bool, timeout time.Duration) *FeesCollector {
 	var initialError error
 	if client == nil {
 		initialError = status.Error(codes.Internal, "gRPC client is nil")
@@ -39,24 +68,62 @@ func NewFeesCollector(client *client.GRPCClient, denom string) *FeesCollector {
 	return &FeesCollector{
 		grpcClient:   client,
 		initialError: initialError,
-		denom:        denom,
+		denoms:       denoms,
+		amountLabels: amountLabels,
+		perValidator: perValidator,
+		timeout:      timeout,
 		feesDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "fees"),
 			"Transaction fees locked in validators.",
 			[]string{"amount", "denom"},
 			prometheus.Labels{"source": "grpc"},
 		),
+		feesAmountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "fees_amount"),
+			"Transaction fees locked in validators, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		commissionAmountDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "fees_commission_amount"),
+			"Commission of all validators not yet withdrawn, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		delegatorRewardsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "fees_delegator_rewards_amount"),
+			"Outstanding rewards of all validators not owed as commission, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		validatorOutstandingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "fees_validator_outstanding_amount"),
+			"Outstanding rewards of a validator, including its commission, in display units.",
+			[]string{"validator", "moniker", "denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		validatorCommissionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "fees_validator_commission_amount"),
+			"Commission of a validator not yet withdrawn, in display units.",
+			[]string{"validator", "moniker", "denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
 		upDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "fees_grpc_up"),
 			"Whether the gRPC query was successful.",
 			nil,
-			prometheus.Labels{"source": "grpc", "queries": "Validators, ValidatorOutstandingRewards"},
+			prometheus.Labels{"source": "grpc", "queries": "Validators, ValidatorOutstandingRewards, ValidatorCommission, DenomMetadata"},
 		),
 	}
 }
 
 func (c *FeesCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.feesDesc
+	ch <- c.feesAmountDesc
+	ch <- c.commissionAmountDesc
+	ch <- c.delegatorRewardsDesc
+	ch <- c.validatorOutstandingDesc
+	ch <- c.validatorCommissionDesc
 	ch <- c.upDesc
 }
 
@@ -65,67 +132,39 @@ func (c *FeesCollector) Collect(ch chan<- prometheus.Metric) {
 	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
 		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
 		collectors.ReportInvalidMetric(ch, c.feesDesc, err)
+		collectors.ReportInvalidMetric(ch, c.feesAmountDesc, err)
 		return
 	}
 
 	stakingQueryClient := stakingv1beta1.NewQueryClient(c.grpcClient.Conn)
-	validatorsResp, validatorsErr := stakingQueryClient.Validators(c.grpcClient.Ctx, &stakingv1beta1.QueryValidatorsRequest{})
+	validators, validatorsErr := queryAllValidators(c.grpcClient.Ctx, stakingQueryClient, c.timeout)
 	if validatorsErr != nil {
 		slog.Error("Failed to query via gRPC", "query", "Validators", "error", validatorsErr)
 		collectors.ReportUpMetric(ch, c.upDesc, 0)
 		collectors.ReportInvalidMetric(ch, c.feesDesc, validatorsErr)
+		collectors.ReportInvalidMetric(ch, c.feesAmountDesc, validatorsErr)
 		return
 	}
 
-	if validatorsResp == nil || validatorsResp.Validators == nil || len(validatorsResp.Validators) == 0 {
+	if len(validators) == 0 {
+		err := status.Error(codes.Internal, "Validators response is nil or empty")
 		collectors.ReportUpMetric(ch, c.upDesc, 0)
-		collectors.ReportInvalidMetric(ch, c.feesDesc, status.Error(codes.Internal, "Validators response is nil or empty"))
+		collectors.ReportInvalidMetric(ch, c.feesDesc, err)
+		collectors.ReportInvalidMetric(ch, c.feesAmountDesc, err)
 		return
 	}
 
 	distributionQueryClient := distributionv1beta1.NewQueryClient(c.grpcClient.Conn)
-	const rpcTimeout = 2 * time.Second
 	eg, egCtx := errgroup.WithContext(c.grpcClient.Ctx)
-	results := make(chan math.Int, len(validatorsResp.Validators))
-	for _, val := range validatorsResp.Validators {
-		val := val
+	eg.SetLimit(feesConcurrency)
+	results := make([]*validatorRewards, len(validators))
+	for i, val := range validators {
 		eg.Go(func() error {
-			callCtx, callCancel := context.WithTimeout(egCtx, rpcTimeout)
-			defer callCancel()
-
-			feesResp, feesErr := distributionQueryClient.ValidatorOutstandingRewards(callCtx, &distributionv1beta1.QueryValidatorOutstandingRewardsRequest{ValidatorAddress: val.OperatorAddress})
-			if feesErr != nil {
-				slog.Error("Failed to query via gRPC", "query", "ValidatorOutstandingRewards", "validator", val.OperatorAddress, "error", feesErr)
-				return feesErr
-			}
-			if feesResp == nil || feesResp.Rewards == nil {
-				slog.Error("ValidatorOutstandingRewards response is nil or empty", "validator", val.OperatorAddress)
-				return status.Error(codes.Internal, "ValidatorOutstandingRewards response is nil or empty")
-			}
-			if len(feesResp.Rewards.Rewards) != 1 {
-				slog.Warn("ValidatorOutstandingRewards response has no rewards or too many rewards", "validator", val.OperatorAddress)
-				return nil
+			rewards, err := c.queryValidatorRewards(egCtx, distributionQueryClient, val)
+			if err != nil {
+				return err
 			}
-			denom := feesResp.Rewards.Rewards[0].Denom
-			if denom != c.denom {
-				slog.Warn("ValidatorOutstandingRewards response has different denom", "validator", val.OperatorAddress, "expected", c.denom, "got", denom)
-				return status.Error(codes.InvalidArgument, "denom mismatch for validator "+val.OperatorAddress+": expected "+c.denom+", got "+denom)
-			}
-
-			// Convert the amount to a big.Int
-			amount, ok := new(big.Int).SetString(feesResp.Rewards.Rewards[0].Amount, 10)
-			if !ok {
-				slog.Error("Failed to parse coin amount", "validator", val.OperatorAddress, "amount", feesResp.Rewards.Rewards[0].Amount)
-				return status.Error(codes.Internal, "invalid coin amount for validator "+val.OperatorAddress+": "+feesResp.Rewards.Rewards[0].Amount)
-			}
-
-			// And create a LegacyDec from it, using the LegacyPrecision
-			legacyAmount := math.LegacyNewDecFromBigIntWithPrec(amount, math.LegacyPrecision)
-
-			// And only keep the integer part
-			truncatedAmount := legacyAmount.TruncateInt()
-			results <- truncatedAmount
-
+			results[i] = rewards
 			return nil
 		})
 	}
@@ -133,28 +172,118 @@ func (c *FeesCollector) Collect(ch chan<- prometheus.Metric) {
 	if err := eg.Wait(); err != nil {
 		collectors.ReportUpMetric(ch, c.upDesc, 0)
 		collectors.ReportInvalidMetric(ch, c.feesDesc, err)
-		close(results)
+		collectors.ReportInvalidMetric(ch, c.feesAmountDesc, err)
 		return
 	}
-	close(results)
 
-	total := math.ZeroInt()
-	for v := range results {
-		total = total.Add(v)
+	// Sum the rewards of all validators, by denom.
+	totals := make(map[string]*big.Int)
+	commissions := make(map[string]*big.Int)
+	for _, denom := range c.denoms {
+		totals[denom] = new(big.Int)
+		commissions[denom] = new(big.Int)
+	}
+	for _, rewards := range results {
+		addCoins(totals, rewards.outstanding)
+		addCoins(commissions, rewards.commission)
+	}
+	// Both maps hold the same denoms, so that every reported denom has a display unit.
+	addCoins(totals, zeroCoins(commissions))
+	addCoins(commissions, zeroCoins(totals))
+
+	if c.amountLabels {
+		for denom, total := range totals {
+			m, err := prometheus.NewConstMetric(c.feesDesc, prometheus.GaugeValue, 1, total.String(), denom)
+			if err != nil {
+				slog.Error("Failed to create fees metric", "error", err)
+				collectors.ReportInvalidMetric(ch, c.feesDesc, err)
+			} else {
+				ch <- m
+			}
+		}
+	}
+
+	units, metaErr := queryDisplayUnits(c.grpcClient.Ctx, bankv1beta1.NewQueryClient(c.grpcClient.Conn), maps.Keys(totals), c.timeout)
+	if metaErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "error", metaErr)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.feesAmountDesc, metaErr)
+		return
 	}
 
 	collectors.ReportUpMetric(ch, c.upDesc, 1)
-	m, err := prometheus.NewConstMetric(c.feesDesc, prometheus.GaugeValue, 1, total.String(), c.denom)
+	for denom, total := range totals {
+		unit := units[denom]
+		commission := commissions[denom]
+		delegatorRewards := new(big.Int).Sub(total, commission)
+		if delegatorRewards.Sign() < 0 {
+			delegatorRewards.SetInt64(0)
+		}
+		collectors.ReportGaugeMetric(ch, c.feesAmountDesc, toDisplayAmount(total, unit.exponent), denom, unit.display)
+		collectors.ReportGaugeMetric(ch, c.commissionAmountDesc, toDisplayAmount(commission, unit.exponent), denom, unit.display)
+		collectors.ReportGaugeMetric(ch, c.delegatorRewardsDesc, toDisplayAmount(delegatorRewards, unit.exponent), denom, unit.display)
+	}
+
+	if c.perValidator {
+		for _, rewards := range results {
+			for denom, amount := range rewards.outstanding {
+				unit := units[denom]
+				collectors.ReportGaugeMetric(ch, c.validatorOutstandingDesc, toDisplayAmount(amount, unit.exponent), rewards.operatorAddress, rewards.moniker, denom, unit.display)
+			}
+			for denom, amount := range rewards.commission {
+				unit := units[denom]
+				collectors.ReportGaugeMetric(ch, c.validatorCommissionDesc, toDisplayAmount(amount, unit.exponent), rewards.operatorAddress, rewards.moniker, denom, unit.display)
+			}
+		}
+	}
+}
+
+// queryValidatorRewards returns the outstanding rewards and commission of a validator.
+func (c *FeesCollector) queryValidatorRewards(ctx context.Context, distributionQueryClient distributionv1beta1.QueryClient, val *stakingv1beta1.Validator) (*validatorRewards, error) {
+	callCtx, callCancel := context.WithTimeout(ctx, c.timeout)
+	defer callCancel()
+
+	feesResp, feesErr := distributionQueryClient.ValidatorOutstandingRewards(callCtx, &distributionv1beta1.QueryValidatorOutstandingRewardsRequest{ValidatorAddress: val.OperatorAddress})
+	if feesErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "ValidatorOutstandingRewards", "validator", val.OperatorAddress, "error", feesErr)
+		return nil, feesErr
+	}
+	if feesResp == nil || feesResp.Rewards == nil {
+		slog.Error("ValidatorOutstandingRewards response is nil or empty", "validator", val.OperatorAddress)
+		return nil, status.Error(codes.Internal, "ValidatorOutstandingRewards response is nil or empty")
+	}
+
+	commissionResp, commissionErr := distributionQueryClient.ValidatorCommission(callCtx, &distributionv1beta1.QueryValidatorCommissionRequest{ValidatorAddress: val.OperatorAddress})
+	if commissionErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "ValidatorCommission", "validator", val.OperatorAddress, "error", commissionErr)
+		return nil, commissionErr
+	}
+
+	outstanding, err := truncateDecCoins(feesResp.Rewards.Rewards)
 	if err != nil {
-		slog.Error("Failed to create fees metric", "error", err)
-		collectors.ReportInvalidMetric(ch, c.feesDesc, err)
-	} else {
-		ch <- m
+		slog.Error("Failed to parse coin amount", "validator", val.OperatorAddress, "error", err)
+		return nil, err
 	}
+	commission, err := truncateDecCoins(commissionResp.GetCommission().GetCommission())
+	if err != nil {
+		slog.Error("Failed to parse coin amount", "validator", val.OperatorAddress, "error", err)
+		return nil, err
+	}
+
+	return &validatorRewards{
+		operatorAddress: val.OperatorAddress,
+		moniker:         val.GetDescription().GetMoniker(),
+		outstanding:     outstanding,
+		commission:      commission,
+	}, nil
 }
 
 func init() {
-	RegisterCollectorFactory("fees", func(client *client.GRPCClient, extra ...interface{}) prometheus.Collector {
-		return NewFeesCollector(client, "umfx")
+	RegisterCollectorFactory("fees", func(client *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := feesOptions{amountOptions: defaultAmountOptions()}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid fees options", "error", err)
+		}
+		return NewFeesCollector(client, cfg.Denoms, opts.AmountLabels, opts.PerValidator, cfg.Timeout)
 	})
 }
@@ -0,0 +1,182 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	distributionv1beta1 "cosmossdk.io/api/cosmos/distribution/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// feesValidators is the number of validators served by the fake fees node, more than feesConcurrency.
+const feesValidators = 10
+
+func feesValidator(i int) string {
+	return fmt.Sprintf("manifestvaloper1val%d", i)
+}
+
+// fakeFeesStakingServer serves feesValidators validators.
+type fakeFeesStakingServer struct {
+	stakingv1beta1.UnimplementedQueryServer
+}
+
+func (fakeFeesStakingServer) Validators(context.Context, *stakingv1beta1.QueryValidatorsRequest) (*stakingv1beta1.QueryValidatorsResponse, error) {
+	var validators []*stakingv1beta1.Validator
+	for i := range feesValidators {
+		validators = append(validators, &stakingv1beta1.Validator{
+			OperatorAddress: feesValidator(i),
+			Description:     &stakingv1beta1.Description{Moniker: fmt.Sprintf("val%d", i)},
+		})
+	}
+	return &stakingv1beta1.QueryValidatorsResponse{Validators: validators}, nil
+}
+
+// fakeDistributionServer serves the rewards of every validator, 1 mfx and 5 upwr each, including 0.25 mfx of commission.
+// The queries of the failing validator fail, and the number of validators queried at once is recorded.
+type fakeDistributionServer struct {
+	distributionv1beta1.UnimplementedQueryServer
+	failing     string
+	inFlight    *atomic.Int32
+	maxInFlight *atomic.Int32
+}
+
+func (s fakeDistributionServer) ValidatorOutstandingRewards(_ context.Context, req *distributionv1beta1.QueryValidatorOutstandingRewardsRequest) (*distributionv1beta1.QueryValidatorOutstandingRewardsResponse, error) {
+	inFlight := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		current := s.maxInFlight.Load()
+		if inFlight <= current || s.maxInFlight.CompareAndSwap(current, inFlight) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if req.ValidatorAddress == s.failing {
+		return nil, status.Error(codes.Unavailable, "node unreachable")
+	}
+	return &distributionv1beta1.QueryValidatorOutstandingRewardsResponse{Rewards: &distributionv1beta1.ValidatorOutstandingRewards{
+		Rewards: []*basev1beta1.DecCoin{
+			{Denom: "umfx", Amount: "1000000.700000000000000000"},
+			{Denom: "upwr", Amount: "5.000000000000000000"},
+		},
+	}}, nil
+}
+
+func (fakeDistributionServer) ValidatorCommission(context.Context, *distributionv1beta1.QueryValidatorCommissionRequest) (*distributionv1beta1.QueryValidatorCommissionResponse, error) {
+	return &distributionv1beta1.QueryValidatorCommissionResponse{Commission: &distributionv1beta1.ValidatorAccumulatedCommission{
+		Commission: []*basev1beta1.DecCoin{{Denom: "umfx", Amount: "250000.000000000000000000"}},
+	}}, nil
+}
+
+// fakeFeesBankServer serves the metadata of umfx, the other denoms having none.
+type fakeFeesBankServer struct {
+	fakeMetadataServer
+}
+
+func (s fakeFeesBankServer) DenomMetadata(ctx context.Context, req *bankv1beta1.QueryDenomMetadataRequest) (*bankv1beta1.QueryDenomMetadataResponse, error) {
+	if req.Denom != "umfx" {
+		return nil, status.Error(codes.NotFound, "no metadata")
+	}
+	return s.fakeMetadataServer.DenomMetadata(ctx, req)
+}
+
+func TestFeesCollector(t *testing.T) {
+	tests := []struct {
+		name         string
+		perValidator bool
+		failing      string
+		wantUp       float64
+		want         map[string]float64
+		notWant      []string
+	}{
+		{
+			name:   "totals of every denom",
+			wantUp: 1,
+			want: map[string]float64{
+				"manifest_tokenomics_fees_amount{denom=umfx,display=mfx}":                   10,
+				"manifest_tokenomics_fees_commission_amount{denom=umfx,display=mfx}":        2.5,
+				"manifest_tokenomics_fees_delegator_rewards_amount{denom=umfx,display=mfx}": 7.5,
+				"manifest_tokenomics_fees_amount{denom=upwr,display=upwr}":                  50,
+				"manifest_tokenomics_fees_commission_amount{denom=upwr,display=upwr}":       0,
+				"manifest_tokenomics_fees_amount{denom=uother,display=uother}":              0,
+			},
+			notWant: []string{
+				"manifest_tokenomics_fees_validator_outstanding_amount{denom=umfx,display=mfx,moniker=val0,validator=manifestvaloper1val0}",
+			},
+		},
+		{
+			name:         "per validator",
+			perValidator: true,
+			wantUp:       1,
+			want: map[string]float64{
+				"manifest_tokenomics_fees_amount{denom=umfx,display=mfx}":                                                                    10,
+				"manifest_tokenomics_fees_validator_outstanding_amount{denom=umfx,display=mfx,moniker=val0,validator=manifestvaloper1val0}":  1,
+				"manifest_tokenomics_fees_validator_outstanding_amount{denom=upwr,display=upwr,moniker=val9,validator=manifestvaloper1val9}": 5,
+				"manifest_tokenomics_fees_validator_commission_amount{denom=umfx,display=mfx,moniker=val3,validator=manifestvaloper1val3}":   0.25,
+			},
+		},
+		{
+			name:         "failing validator query",
+			perValidator: true,
+			failing:      feesValidator(4),
+			wantUp:       0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, maxInFlight atomic.Int32
+			grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+				stakingv1beta1.RegisterQueryServer(server, fakeFeesStakingServer{})
+				distributionv1beta1.RegisterQueryServer(server, fakeDistributionServer{failing: tt.failing, inFlight: &inFlight, maxInFlight: &maxInFlight})
+				bankv1beta1.RegisterQueryServer(server, fakeFeesBankServer{})
+			})
+			collector := NewFeesCollector(grpcClient, []string{"umfx", "uother"}, false, tt.perValidator, time.Second)
+
+			if tt.wantUp == 0 {
+				ch := make(chan prometheus.Metric, 16)
+				collector.Collect(ch)
+				close(ch)
+				up := -1.0
+				for metric := range ch {
+					var m dto.Metric
+					if metric.Desc() == collector.upDesc && metric.Write(&m) == nil {
+						up = m.GetGauge().GetValue()
+					}
+					if metric.Desc() == collector.validatorOutstandingDesc {
+						t.Error("per-validator rewards reported after a failed validator query")
+					}
+				}
+				if up != tt.wantUp {
+					t.Errorf("fees_grpc_up = %v, want %v", up, tt.wantUp)
+				}
+				return
+			}
+
+			got := gatherValues(t, collector)
+			assertValues(t, got, tt.want)
+			assertValues(t, got, map[string]float64{"manifest_tokenomics_fees_grpc_up": tt.wantUp})
+			for _, key := range tt.notWant {
+				if _, ok := got[key]; ok {
+					t.Errorf("%s reported", key)
+				}
+			}
+			if n := maxInFlight.Load(); n > feesConcurrency {
+				t.Errorf("validators queried at once = %d, want at most %d", n, feesConcurrency)
+			}
+		})
+	}
+}
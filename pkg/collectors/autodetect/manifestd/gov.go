@@ -0,0 +1,319 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"math/big"
+	"slices"
+	"strconv"
+	"time"
+
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
+	govv1 "cosmossdk.io/api/cosmos/gov/v1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// defaultMaxProposals is the default number of proposals producing per-proposal series.
+const defaultMaxProposals = 20
+
+// govOptions holds the collector-specific options of the governance collector.
+type govOptions struct {
+	MaxProposals int `mapstructure:"max_proposals"` // Number of most recent proposals producing per-proposal series
+}
+
+// GovCollector collects the proposals in deposit or voting period from the Cosmos SDK gov module via gRPC.
+type GovCollector struct {
+	grpcClient     *client.GRPCClient
+	proposalsDesc  *prometheus.Desc // Number of proposals by status
+	infoDesc       *prometheus.Desc // Per-proposal title
+	statusDesc     *prometheus.Desc // Per-proposal status
+	depositEndDesc *prometheus.Desc // Per-proposal deposit end time
+	votingEndDesc  *prometheus.Desc // Per-proposal voting end time
+	tallyDesc      *prometheus.Desc // Per-proposal tally by vote option
+	turnoutDesc    *prometheus.Desc // Per-proposal ratio of bonded tokens that voted
+	quorumDesc     *prometheus.Desc // Quorum required for a proposal to be valid
+	upDesc         *prometheus.Desc // gRPC query success
+	maxProposals   int
+	timeout        time.Duration
+	initialError   error
+}
+
+// NewGovCollector creates a new GovCollector.
+// It requires a gRPC client connection to query the gov and staking modules.
+// Only the maxProposals most recent proposals produce per-proposal series.
+func NewGovCollector(client *client.GRPCClient, maxProposals int, timeout time.Duration) *GovCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	return &GovCollector{
+		grpcClient:   client,
+		initialError: initialError,
+		maxProposals: maxProposals,
+		timeout:      timeout,
+		proposalsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposals"),
+			"Number of proposals in deposit or voting period, by status.",
+			[]string{"status"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposal_info"),
+			"Information about a proposal in deposit or voting period (title).",
+			[]string{"proposal_id", "title"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		statusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposal_status"),
+			"Status of a proposal (1: deposit period, 2: voting period).",
+			[]string{"proposal_id"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		depositEndDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposal_deposit_end_time"),
+			"Unix timestamp of the end of the deposit period of a proposal.",
+			[]string{"proposal_id"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		votingEndDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposal_voting_end_time"),
+			"Unix timestamp of the end of the voting period of a proposal.",
+			[]string{"proposal_id"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		tallyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposal_tally"),
+			"Voting power cast on a proposal in voting period, in base units of the bond denom, by vote option.",
+			[]string{"proposal_id", "option"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		turnoutDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "proposal_turnout"),
+			"Ratio of the bonded tokens that voted on a proposal in voting period.",
+			[]string{"proposal_id"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		quorumDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "quorum"),
+			"Minimum turnout for the result of a proposal to be valid.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "gov", "grpc_up"),
+			"Whether the gRPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "Proposals, TallyResult, Params, Pool"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *GovCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.proposalsDesc
+	ch <- c.infoDesc
+	ch <- c.statusDesc
+	ch <- c.depositEndDesc
+	ch <- c.votingEndDesc
+	ch <- c.tallyDesc
+	ch <- c.turnoutDesc
+	ch <- c.quorumDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *GovCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.proposalsDesc, err)
+		collectors.ReportInvalidMetric(ch, c.quorumDesc, err)
+		return
+	}
+
+	govQueryClient := govv1.NewQueryClient(c.grpcClient.Conn)
+
+	depositProposals, depositErr := c.queryProposals(govQueryClient, govv1.ProposalStatus_PROPOSAL_STATUS_DEPOSIT_PERIOD)
+	if depositErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Proposals", "status", "deposit_period", "error", depositErr)
+	}
+	votingProposals, votingErr := c.queryProposals(govQueryClient, govv1.ProposalStatus_PROPOSAL_STATUS_VOTING_PERIOD)
+	if votingErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Proposals", "status", "voting_period", "error", votingErr)
+	}
+
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	paramsResp, paramsErr := govQueryClient.Params(ctx, &govv1.QueryParamsRequest{ParamsType: "tallying"})
+	if paramsErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Params", "error", paramsErr)
+	}
+
+	poolResp, poolErr := stakingv1beta1.NewQueryClient(c.grpcClient.Conn).Pool(ctx, &stakingv1beta1.QueryPoolRequest{})
+	if poolErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Pool", "error", poolErr)
+	}
+
+	if depositErr != nil || votingErr != nil {
+		err := depositErr
+		if err == nil {
+			err = votingErr
+		}
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.proposalsDesc, err)
+		return
+	}
+
+	upValue := 1.0
+	if paramsErr != nil || poolErr != nil {
+		upValue = 0
+	}
+
+	if paramsErr != nil {
+		collectors.ReportInvalidMetric(ch, c.quorumDesc, paramsErr)
+	} else if err := c.collectQuorum(ch, paramsResp); err != nil {
+		collectors.ReportInvalidMetric(ch, c.quorumDesc, err)
+		upValue = 0
+	}
+
+	collectors.ReportGaugeMetric(ch, c.proposalsDesc, float64(len(depositProposals)), "deposit_period")
+	collectors.ReportGaugeMetric(ch, c.proposalsDesc, float64(len(votingProposals)), "voting_period")
+
+	// Keep the most recent proposals to bound the number of series.
+	proposals := append(depositProposals, votingProposals...)
+	slices.SortFunc(proposals, func(a, b *govv1.Proposal) int {
+		return cmp.Compare(b.Id, a.Id)
+	})
+	if len(proposals) > c.maxProposals {
+		slog.Warn("Too many open proposals, reporting the most recent ones", "count", len(proposals), "max_proposals", c.maxProposals)
+		proposals = proposals[:c.maxProposals]
+	}
+
+	var bonded *big.Int
+	if poolErr == nil {
+		var err error
+		if bonded, err = parseAmount(poolResp.GetPool().GetBondedTokens()); err != nil {
+			slog.Error("Failed to parse bonded tokens", "error", err)
+		}
+	}
+
+	for _, proposal := range proposals {
+		if err := c.collectProposal(ch, govQueryClient, proposal, bonded); err != nil {
+			collectors.ReportInvalidMetric(ch, c.tallyDesc, err)
+			upValue = 0
+		}
+	}
+
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+// queryProposals returns all the proposals with the given status.
+func (c *GovCollector) queryProposals(govQueryClient govv1.QueryClient, proposalStatus govv1.ProposalStatus) ([]*govv1.Proposal, error) {
+	var proposals []*govv1.Proposal
+	err := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+		defer cancel()
+
+		resp, err := govQueryClient.Proposals(ctx, &govv1.QueryProposalsRequest{ProposalStatus: proposalStatus, Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, resp.Proposals...)
+		return resp.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+func (c *GovCollector) collectQuorum(ch chan<- prometheus.Metric, resp *govv1.QueryParamsResponse) error {
+	// Older nodes only fill the deprecated tally params.
+	quorum := resp.GetParams().GetQuorum()
+	if quorum == "" {
+		quorum = resp.GetTallyParams().GetQuorum()
+	}
+	value, err := parseLegacyDec(quorum)
+	if err != nil {
+		return err
+	}
+	quorumValue, _ := value.Float64()
+	collectors.ReportGaugeMetric(ch, c.quorumDesc, quorumValue)
+	return nil
+}
+
+func (c *GovCollector) collectProposal(ch chan<- prometheus.Metric, govQueryClient govv1.QueryClient, proposal *govv1.Proposal, bonded *big.Int) error {
+	id := strconv.FormatUint(proposal.Id, 10)
+	collectors.ReportGaugeMetric(ch, c.infoDesc, 1, id, proposal.Title)
+	collectors.ReportGaugeMetric(ch, c.statusDesc, float64(proposal.Status), id)
+	collectors.ReportGaugeMetric(ch, c.depositEndDesc, float64(proposal.GetDepositEndTime().AsTime().Unix()), id)
+
+	if proposal.Status != govv1.ProposalStatus_PROPOSAL_STATUS_VOTING_PERIOD {
+		return nil
+	}
+	collectors.ReportGaugeMetric(ch, c.votingEndDesc, float64(proposal.GetVotingEndTime().AsTime().Unix()), id)
+
+	// The tally stored in the proposal is only final once voting ends.
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	resp, err := govQueryClient.TallyResult(ctx, &govv1.QueryTallyResultRequest{ProposalId: proposal.Id})
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "TallyResult", "proposal_id", id, "error", err)
+		return err
+	}
+
+	tally := resp.GetTally()
+	options := []struct {
+		name  string
+		count string
+	}{
+		{"yes", tally.GetYesCount()},
+		{"no", tally.GetNoCount()},
+		{"abstain", tally.GetAbstainCount()},
+		{"no_with_veto", tally.GetNoWithVetoCount()},
+	}
+	total := new(big.Int)
+	for _, option := range options {
+		count, err := parseAmount(cmp.Or(option.count, "0"))
+		if err != nil {
+			return err
+		}
+		total.Add(total, count)
+		collectors.ReportGaugeMetric(ch, c.tallyDesc, toDisplayAmount(count, 0), id, option.name)
+	}
+
+	if bonded != nil && bonded.Sign() > 0 {
+		turnout, _ := new(big.Rat).SetFrac(total, bonded).Float64()
+		collectors.ReportGaugeMetric(ch, c.turnoutDesc, turnout, id)
+	}
+	return nil
+}
+
+func init() {
+	RegisterCollectorFactory("gov", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := govOptions{MaxProposals: defaultMaxProposals}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid gov options", "error", err)
+		}
+		if opts.MaxProposals < 0 {
+			slog.Error("Invalid gov options, max_proposals must not be negative", "max_proposals", opts.MaxProposals)
+			opts.MaxProposals = defaultMaxProposals
+		}
+		return NewGovCollector(grpcClient, opts.MaxProposals, cfg.Timeout)
+	})
+}
@@ -0,0 +1,104 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	govv1 "cosmossdk.io/api/cosmos/gov/v1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"google.golang.org/grpc"
+)
+
+// fakeGovServer serves proposals 1 and 4 in deposit period, and proposals 2 and 3 in voting period.
+type fakeGovServer struct {
+	govv1.UnimplementedQueryServer
+}
+
+func (fakeGovServer) Proposals(_ context.Context, req *govv1.QueryProposalsRequest) (*govv1.QueryProposalsResponse, error) {
+	ids := map[govv1.ProposalStatus][]uint64{
+		govv1.ProposalStatus_PROPOSAL_STATUS_DEPOSIT_PERIOD: {1, 4},
+		govv1.ProposalStatus_PROPOSAL_STATUS_VOTING_PERIOD:  {2, 3},
+	}[req.ProposalStatus]
+	titles := map[uint64]string{1: "Fund the relayers", 2: "Raise the block size", 3: "Upgrade to v2", 4: "Add a denom"}
+
+	var proposals []*govv1.Proposal
+	for _, id := range ids {
+		proposals = append(proposals, &govv1.Proposal{Id: id, Title: titles[id], Status: req.ProposalStatus})
+	}
+	return &govv1.QueryProposalsResponse{Proposals: proposals}, nil
+}
+
+func (fakeGovServer) TallyResult(context.Context, *govv1.QueryTallyResultRequest) (*govv1.QueryTallyResultResponse, error) {
+	return &govv1.QueryTallyResultResponse{Tally: &govv1.TallyResult{YesCount: "1000000", NoCount: "250000"}}, nil
+}
+
+func (fakeGovServer) Params(context.Context, *govv1.QueryParamsRequest) (*govv1.QueryParamsResponse, error) {
+	return &govv1.QueryParamsResponse{Params: &govv1.Params{Quorum: "0.334000000000000000"}}, nil
+}
+
+func TestGovCollectorMaxProposals(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxProposals int
+		want         map[string]float64
+		notWant      []string // Series of the proposals left out
+	}{
+		{
+			name:         "every proposal",
+			maxProposals: 10,
+			want: map[string]float64{
+				"manifest_gov_proposal_info{proposal_id=1,title=Fund the relayers}":    1,
+				"manifest_gov_proposal_info{proposal_id=2,title=Raise the block size}": 1,
+				"manifest_gov_proposal_info{proposal_id=3,title=Upgrade to v2}":        1,
+				"manifest_gov_proposal_info{proposal_id=4,title=Add a denom}":          1,
+				"manifest_gov_proposal_status{proposal_id=2}":                          2,
+				"manifest_gov_proposal_tally{option=yes,proposal_id=2}":                1000000,
+				"manifest_gov_proposal_turnout{proposal_id=2}":                         0.5,
+			},
+		},
+		{
+			name:         "truncated to the most recent proposals",
+			maxProposals: 2,
+			want: map[string]float64{
+				"manifest_gov_proposal_info{proposal_id=3,title=Upgrade to v2}": 1,
+				"manifest_gov_proposal_info{proposal_id=4,title=Add a denom}":   1,
+				"manifest_gov_proposal_status{proposal_id=4}":                   1,
+				"manifest_gov_proposal_tally{option=no,proposal_id=3}":          250000,
+			},
+			notWant: []string{
+				"manifest_gov_proposal_info{proposal_id=1,title=Fund the relayers}",
+				"manifest_gov_proposal_info{proposal_id=2,title=Raise the block size}",
+				"manifest_gov_proposal_status{proposal_id=2}",
+				"manifest_gov_proposal_tally{option=yes,proposal_id=2}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+				govv1.RegisterQueryServer(server, fakeGovServer{})
+				stakingv1beta1.RegisterQueryServer(server, fakeStakingServer{})
+			})
+			got := gatherValues(t, NewGovCollector(grpcClient, tt.maxProposals, time.Second))
+
+			// The proposal counts are not truncated.
+			assertValues(t, got, map[string]float64{
+				"manifest_gov_proposals{status=deposit_period}": 2,
+				"manifest_gov_proposals{status=voting_period}":  2,
+				"manifest_gov_quorum":                           0.334,
+				"manifest_gov_grpc_up":                          1,
+			})
+			assertValues(t, got, tt.want)
+			for _, key := range tt.notWant {
+				if _, ok := got[key]; ok {
+					t.Errorf("%s reported beyond max_proposals", key)
+				}
+			}
+		})
+	}
+}
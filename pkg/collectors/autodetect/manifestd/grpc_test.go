@@ -0,0 +1,130 @@
+package manifestd
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"github.com/cosmos/btcutil/bech32"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+)
+
+// newFakeGRPCClient starts a gRPC server with the services registered by register,
+// and returns a client connected to it. Both are stopped at the end of the test.
+func newFakeGRPCClient(t *testing.T, register func(server *grpc.Server)) *client.GRPCClient {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	grpcClient, err := client.NewGRPCClient(context.Background(), listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = grpcClient.Close() })
+	return grpcClient
+}
+
+// fakeStakingServer serves a single bonded validator, the staking pool and the umfx bond denom.
+type fakeStakingServer struct {
+	stakingv1beta1.UnimplementedQueryServer
+}
+
+func (fakeStakingServer) Validators(context.Context, *stakingv1beta1.QueryValidatorsRequest) (*stakingv1beta1.QueryValidatorsResponse, error) {
+	return &stakingv1beta1.QueryValidatorsResponse{Validators: []*stakingv1beta1.Validator{{
+		OperatorAddress: "manifestvaloper1abc",
+		Status:          stakingv1beta1.BondStatus_BOND_STATUS_BONDED,
+		Tokens:          "2500000",
+		Description:     &stakingv1beta1.Description{Moniker: "alice"},
+		Commission:      &stakingv1beta1.Commission{CommissionRates: &stakingv1beta1.CommissionRates{Rate: "0.050000000000000000"}},
+	}}}, nil
+}
+
+func (fakeStakingServer) Pool(context.Context, *stakingv1beta1.QueryPoolRequest) (*stakingv1beta1.QueryPoolResponse, error) {
+	return &stakingv1beta1.QueryPoolResponse{Pool: &stakingv1beta1.Pool{BondedTokens: "2500000", NotBondedTokens: "500000"}}, nil
+}
+
+func (fakeStakingServer) Params(context.Context, *stakingv1beta1.QueryParamsRequest) (*stakingv1beta1.QueryParamsResponse, error) {
+	return &stakingv1beta1.QueryParamsResponse{Params: &stakingv1beta1.Params{BondDenom: "umfx"}}, nil
+}
+
+// fakeMetadataServer serves the denom metadata of umfx, displayed as mfx.
+type fakeMetadataServer struct {
+	bankv1beta1.UnimplementedQueryServer
+}
+
+func (fakeMetadataServer) DenomMetadata(context.Context, *bankv1beta1.QueryDenomMetadataRequest) (*bankv1beta1.QueryDenomMetadataResponse, error) {
+	return &bankv1beta1.QueryDenomMetadataResponse{Metadata: &bankv1beta1.Metadata{
+		Base:    "umfx",
+		Display: "mfx",
+		DenomUnits: []*bankv1beta1.DenomUnit{
+			{Denom: "umfx", Exponent: 0},
+			{Denom: "mfx", Exponent: 6},
+		},
+	}}, nil
+}
+
+// testAddress returns a valid bech32 address with the given prefix, whose last byte is b.
+func testAddress(t *testing.T, prefix string, b byte) string {
+	t.Helper()
+	data := make([]byte, 20)
+	data[19] = b
+	address, err := bech32.EncodeFromBase256(prefix, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+// gatherValues collects the collector and returns its values, keyed by metric name and sorted label pairs.
+func gatherValues(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	t.Helper()
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			var labels []string
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "source" || label.GetName() == "queries" {
+					continue
+				}
+				labels = append(labels, label.GetName()+"="+label.GetValue())
+			}
+			sort.Strings(labels)
+			key := family.GetName()
+			if len(labels) > 0 {
+				key += "{" + strings.Join(labels, ",") + "}"
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+	return values
+}
+
+// assertValues checks that got holds every value of want.
+func assertValues(t *testing.T, got, want map[string]float64) {
+	t.Helper()
+	for key, value := range want {
+		if v, ok := got[key]; !ok || v != value {
+			t.Errorf("%s = %v (present: %v), want %v", key, v, ok, value)
+		}
+	}
+}
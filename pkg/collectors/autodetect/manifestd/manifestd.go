@@ -3,17 +3,23 @@ package manifestd
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net"
 	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
 
 	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
 	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect"
 )
 
 const processName = "manifestd"
-const defaultPort = 9090 // Default gRPC port for manifestd
+const defaultPort = 9090                 // Default gRPC port for manifestd
+const defaultRPCPort = 26657             // Default CometBFT RPC port for manifestd
+const grpcEndpointKey = "manifestd-grpc" // Configuration key of the explicit gRPC endpoint
+const rpcEndpointKey = "manifestd-rpc"   // Configuration key of the explicit CometBFT RPC endpoint
 
 // Ensure manifestdMonitor implements ProcessMonitor
 var _ autodetect.ProcessMonitor = (*manifestdMonitor)(nil)
@@ -30,18 +36,46 @@ func (m *manifestdMonitor) Name() string {
 }
 
 // Detect checks if the monitored process is running, validates its gRPC readiness, and retrieves process information.
+// If an explicit gRPC endpoint is configured, process detection is skipped and only the endpoint is validated.
+// The CometBFT RPC endpoint is detected among the listening ports of the process, unless configured explicitly,
+// and only when RPC collectors are registered.
 func (m *manifestdMonitor) Detect() (*autodetect.ProcessInfo, error) {
-	return autodetect.DetectProcessWithGrpc(processName, defaultPort)
+	var processInfo *autodetect.ProcessInfo
+	var err error
+	if endpoint := viper.GetString(grpcEndpointKey); endpoint != "" {
+		processInfo, err = autodetect.DetectGrpcEndpoint(processName, endpoint)
+	} else {
+		processInfo, err = autodetect.DetectProcessWithGrpc(processName, defaultPort)
+	}
+	if processInfo == nil || len(GetAllRPCCollectorFactories()) == 0 {
+		return processInfo, err
+	}
+
+	if endpoint := viper.GetString(rpcEndpointKey); endpoint != "" {
+		processInfo.RPC = endpoint
+	} else if processInfo.Pid != 0 {
+		rpc, rpcErr := autodetect.DetectRPCPort(processInfo.Pid, defaultRPCPort)
+		if rpcErr != nil {
+			slog.Warn("Failed to detect the CometBFT RPC port", "name", processName, "pid", processInfo.Pid, "error", rpcErr)
+		}
+		processInfo.RPC = rpc
+	}
+	return processInfo, err
 }
 
 // CollectCollectors gathers all registered Prometheus collectors for the manifestd process using a provided gRPC client.
 // It requires valid process information to establish a gRPC connection.
+// RPC collectors are added when the CometBFT RPC endpoint was detected.
+// The gRPC and RPC clients are closed once ctx is cancelled.
 // Returns a slice of Prometheus collectors or an error if the process information is nil or the gRPC client cannot be created.
 func (m *manifestdMonitor) CollectCollectors(ctx context.Context, processInfo *autodetect.ProcessInfo) ([]prometheus.Collector, error) {
 	if processInfo == nil {
 		return nil, fmt.Errorf("processInfo is nil")
 	}
 
+	// The collectors reading files of the node find the detected process through the client context.
+	ctx = withProcessInfo(ctx, processInfo)
+
 	// ProcessInfo should contain the necessary information to create a gRPC client
 	target := net.JoinHostPort(processInfo.Address, strconv.Itoa(int(processInfo.Port)))
 	grpcClient, err := client.NewGRPCClient(ctx, target)
@@ -49,9 +83,46 @@ func (m *manifestdMonitor) CollectCollectors(ctx context.Context, processInfo *a
 		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
 	}
 
+	// The gRPC client lives as long as the detection context.
+	go func() {
+		<-ctx.Done()
+		if err := grpcClient.Close(); err != nil {
+			slog.Warn("Failed to close gRPC client", "target", target, "error", err)
+		}
+	}()
+
 	var resultCollectors []prometheus.Collector
-	for _, collector := range GetAllCollectorFactories() {
-		resultCollectors = append(resultCollectors, collector(grpcClient))
+	for name, factory := range GetAllCollectorFactories() {
+		if collector := collectors.NewConfiguredCollector(ctx, name, name, func(cfg collectors.CollectorConfig) prometheus.Collector {
+			return factory(grpcClient, cfg)
+		}); collector != nil {
+			resultCollectors = append(resultCollectors, collector)
+		}
+	}
+
+	rpcFactories := GetAllRPCCollectorFactories()
+	if len(rpcFactories) == 0 {
+		return resultCollectors, nil
+	}
+	if processInfo.RPC == "" {
+		slog.Warn("CometBFT RPC endpoint not found, skipping RPC collectors", "name", processName)
+		return resultCollectors, nil
+	}
+
+	rpcClient := client.NewRPCClient(ctx, processInfo.RPC)
+	go func() {
+		<-ctx.Done()
+		if err := rpcClient.Close(); err != nil {
+			slog.Warn("Failed to close RPC client", "endpoint", rpcClient.Endpoint, "error", err)
+		}
+	}()
+
+	for name, factory := range rpcFactories {
+		if collector := collectors.NewConfiguredCollector(ctx, name, name, func(cfg collectors.CollectorConfig) prometheus.Collector {
+			return factory(rpcClient, cfg)
+		}); collector != nil {
+			resultCollectors = append(resultCollectors, collector)
+		}
 	}
 
 	return resultCollectors, nil
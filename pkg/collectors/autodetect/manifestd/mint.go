@@ -0,0 +1,234 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	mintv1beta1 "cosmossdk.io/api/cosmos/mint/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// Reasons reported by the mint collector status metric.
+const (
+	mintReasonOK              = "ok"                   // All queries succeeded
+	mintReasonClientError     = "client_error"         // The gRPC client is not usable
+	mintReasonModuleNotFound  = "module_not_available" // The running binary has no mint module
+	mintReasonQueryFailed     = "query_failed"         // A query failed
+	mintReasonInvalidResponse = "invalid_response"     // A response could not be parsed
+)
+
+// MintCollector collects the inflation, annual provisions and parameters of the Cosmos SDK mint module via gRPC.
+type MintCollector struct {
+	grpcClient              *client.GRPCClient
+	inflationDesc           *prometheus.Desc // Current inflation rate
+	annualProvisionsDesc    *prometheus.Desc // Annual provisions, in display units
+	inflationRateChangeDesc *prometheus.Desc // Maximum annual change of the inflation rate
+	inflationMaxDesc        *prometheus.Desc // Maximum inflation rate
+	inflationMinDesc        *prometheus.Desc // Minimum inflation rate
+	goalBondedDesc          *prometheus.Desc // Goal of bonded tokens ratio
+	blocksPerYearDesc       *prometheus.Desc // Expected blocks per year
+	statusDesc              *prometheus.Desc // Collection status, by reason
+	upDesc                  *prometheus.Desc // gRPC query success
+	timeout                 time.Duration
+	initialError            error
+}
+
+// NewMintCollector creates a new MintCollector.
+// It requires a gRPC client connection to query the mint module.
+func NewMintCollector(client *client.GRPCClient, timeout time.Duration) *MintCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	return &MintCollector{
+		grpcClient:   client,
+		initialError: initialError,
+		timeout:      timeout,
+		inflationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "inflation"),
+			"Current annual inflation rate.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		annualProvisionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "annual_provisions_amount"),
+			"Current annual provisions, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		inflationRateChangeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "inflation_rate_change"),
+			"Maximum annual change of the inflation rate.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		inflationMaxDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "inflation_max"),
+			"Maximum annual inflation rate.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		inflationMinDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "inflation_min"),
+			"Minimum annual inflation rate.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		goalBondedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "goal_bonded"),
+			"Goal of the bonded tokens ratio.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		blocksPerYearDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "blocks_per_year"),
+			"Expected number of blocks per year.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		statusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "status"),
+			"Status of the last collection, always 1. The reason label explains a failure, or is ok.",
+			[]string{"reason"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "mint", "grpc_up"),
+			"Whether the gRPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "Params, Inflation, AnnualProvisions, DenomMetadata"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *MintCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.inflationDesc
+	ch <- c.annualProvisionsDesc
+	ch <- c.inflationRateChangeDesc
+	ch <- c.inflationMaxDesc
+	ch <- c.inflationMinDesc
+	ch <- c.goalBondedDesc
+	ch <- c.blocksPerYearDesc
+	ch <- c.statusDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *MintCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		c.reportFailure(ch, mintReasonClientError, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	mintQueryClient := mintv1beta1.NewQueryClient(c.grpcClient.Conn)
+	paramsResp, err := mintQueryClient.Params(ctx, &mintv1beta1.QueryParamsRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		// Not an error: the chain may run without inflation.
+		slog.Debug("Mint module not available", "error", err)
+		c.reportStatus(ch, 0, mintReasonModuleNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "Params", "error", err)
+		c.reportFailure(ch, mintReasonQueryFailed, err)
+		return
+	}
+
+	inflationResp, err := mintQueryClient.Inflation(ctx, &mintv1beta1.QueryInflationRequest{})
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "Inflation", "error", err)
+		c.reportFailure(ch, mintReasonQueryFailed, err)
+		return
+	}
+
+	provisionsResp, err := mintQueryClient.AnnualProvisions(ctx, &mintv1beta1.QueryAnnualProvisionsRequest{})
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "AnnualProvisions", "error", err)
+		c.reportFailure(ch, mintReasonQueryFailed, err)
+		return
+	}
+
+	params := paramsResp.GetParams()
+	if params == nil {
+		c.reportFailure(ch, mintReasonInvalidResponse, status.Error(codes.Internal, "mint params response is nil"))
+		return
+	}
+
+	display, exponent, err := queryDisplayUnit(ctx, bankv1beta1.NewQueryClient(c.grpcClient.Conn), params.MintDenom)
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "denom", params.MintDenom, "error", err)
+		c.reportFailure(ch, mintReasonQueryFailed, err)
+		return
+	}
+
+	rates := []struct {
+		desc  *prometheus.Desc
+		value string
+	}{
+		{c.inflationDesc, string(inflationResp.Inflation)},
+		{c.inflationRateChangeDesc, params.InflationRateChange},
+		{c.inflationMaxDesc, params.InflationMax},
+		{c.inflationMinDesc, params.InflationMin},
+		{c.goalBondedDesc, params.GoalBonded},
+	}
+	values := make([]float64, len(rates))
+	for i, rate := range rates {
+		dec, err := parseLegacyDec(rate.value)
+		if err != nil {
+			slog.Error("Failed to parse mint decimal", "value", rate.value, "error", err)
+			c.reportFailure(ch, mintReasonInvalidResponse, err)
+			return
+		}
+		values[i], _ = dec.Float64()
+	}
+
+	provisions, err := parseLegacyDec(string(provisionsResp.AnnualProvisions))
+	if err != nil {
+		slog.Error("Failed to parse annual provisions", "error", err)
+		c.reportFailure(ch, mintReasonInvalidResponse, err)
+		return
+	}
+
+	c.reportStatus(ch, 1, mintReasonOK)
+	for i, rate := range rates {
+		collectors.ReportGaugeMetric(ch, rate.desc, values[i])
+	}
+	collectors.ReportGaugeMetric(ch, c.blocksPerYearDesc, float64(params.BlocksPerYear))
+	collectors.ReportGaugeMetric(ch, c.annualProvisionsDesc, toDisplayAmount(provisions.TruncateInt().BigInt(), exponent), params.MintDenom, display)
+}
+
+// reportStatus reports the 'up' metric and the status with the given reason.
+func (c *MintCollector) reportStatus(ch chan<- prometheus.Metric, up float64, reason string) {
+	collectors.ReportUpMetric(ch, c.upDesc, up)
+	collectors.ReportGaugeMetric(ch, c.statusDesc, 1, reason)
+}
+
+// reportFailure reports the 'up' metric as down with the given reason, and the error as an invalid metric.
+func (c *MintCollector) reportFailure(ch chan<- prometheus.Metric, reason string, err error) {
+	c.reportStatus(ch, 0, reason)
+	collectors.ReportInvalidMetric(ch, c.inflationDesc, err)
+}
+
+func init() {
+	RegisterCollectorFactory("mint", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		return NewMintCollector(grpcClient, cfg.Timeout)
+	})
+}
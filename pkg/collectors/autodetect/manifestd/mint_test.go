@@ -0,0 +1,75 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	mintv1beta1 "cosmossdk.io/api/cosmos/mint/v1beta1"
+	"google.golang.org/grpc"
+)
+
+// fakeMintServer serves the mint queries used by the mint collector.
+type fakeMintServer struct {
+	mintv1beta1.UnimplementedQueryServer
+}
+
+func (fakeMintServer) Params(context.Context, *mintv1beta1.QueryParamsRequest) (*mintv1beta1.QueryParamsResponse, error) {
+	return &mintv1beta1.QueryParamsResponse{Params: &mintv1beta1.Params{
+		MintDenom:           "umfx",
+		InflationRateChange: "0.130000000000000000",
+		InflationMax:        "0.200000000000000000",
+		InflationMin:        "0.070000000000000000",
+		GoalBonded:          "0.670000000000000000",
+		BlocksPerYear:       6311520,
+	}}, nil
+}
+
+func (fakeMintServer) Inflation(context.Context, *mintv1beta1.QueryInflationRequest) (*mintv1beta1.QueryInflationResponse, error) {
+	return &mintv1beta1.QueryInflationResponse{Inflation: []byte("0.100000000000000000")}, nil
+}
+
+func (fakeMintServer) AnnualProvisions(context.Context, *mintv1beta1.QueryAnnualProvisionsRequest) (*mintv1beta1.QueryAnnualProvisionsResponse, error) {
+	return &mintv1beta1.QueryAnnualProvisionsResponse{AnnualProvisions: []byte("1500000.000000000000000000")}, nil
+}
+
+func TestMintCollectorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(server *grpc.Server)
+		want     map[string]float64
+	}{
+		{
+			name: "mint module available",
+			register: func(server *grpc.Server) {
+				mintv1beta1.RegisterQueryServer(server, fakeMintServer{})
+				bankv1beta1.RegisterQueryServer(server, fakeMetadataServer{})
+			},
+			want: map[string]float64{
+				"manifest_mint_grpc_up":                                          1,
+				"manifest_mint_status{reason=ok}":                                1,
+				"manifest_mint_inflation":                                        0.1,
+				"manifest_mint_annual_provisions_amount{denom=umfx,display=mfx}": 1.5,
+			},
+		},
+		{
+			name:     "mint module not available",
+			register: func(*grpc.Server) {},
+			want: map[string]float64{
+				"manifest_mint_grpc_up":                             0,
+				"manifest_mint_status{reason=module_not_available}": 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gatherValues(t, NewMintCollector(newFakeGRPCClient(t, tt.register), time.Second))
+			assertValues(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	distributionv1beta1 "cosmossdk.io/api/cosmos/distribution/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// moduleAccountsOptions holds the collector-specific options of the module accounts collector.
+type moduleAccountsOptions struct {
+	Modules []string `mapstructure:"modules"` // Names of the module accounts to report
+}
+
+// defaultModuleAccounts are the well-known module accounts holding tokens.
+var defaultModuleAccounts = []string{"distribution", "fee_collector", "bonded_tokens_pool", "not_bonded_tokens_pool", "mint", "gov"}
+
+// ModuleAccountsCollector collects the community pool from the Cosmos SDK distribution module,
+// and the balances of module accounts from the bank module, via gRPC.
+type ModuleAccountsCollector struct {
+	grpcClient        *client.GRPCClient
+	communityPoolDesc *prometheus.Desc // Community pool, in display units
+	moduleBalanceDesc *prometheus.Desc // Module account balance, in display units
+	upDesc            *prometheus.Desc // gRPC query success
+	modules           []string
+	timeout           time.Duration
+	initialError      error
+}
+
+// NewModuleAccountsCollector creates a new ModuleAccountsCollector.
+// It requires a gRPC client connection to query the auth, bank and distribution modules.
+// Modules without a module account on chain are ignored.
+func NewModuleAccountsCollector(client *client.GRPCClient, modules []string, timeout time.Duration) *ModuleAccountsCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	return &ModuleAccountsCollector{
+		grpcClient:   client,
+		initialError: initialError,
+		modules:      modules,
+		timeout:      timeout,
+		communityPoolDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "community_pool_amount"),
+			"Community pool, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		moduleBalanceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "module_account_balance_amount"),
+			"Balance of a module account, in display units.",
+			[]string{"module", "address", "denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "tokenomics", "module_accounts_grpc_up"),
+			"Whether the gRPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "CommunityPool, ModuleAccounts, AllBalances, DenomMetadata"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *ModuleAccountsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.communityPoolDesc
+	ch <- c.moduleBalanceDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ModuleAccountsCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.communityPoolDesc, err)
+		collectors.ReportInvalidMetric(ch, c.moduleBalanceDesc, err)
+		return
+	}
+
+	communityPool, poolErr := c.queryCommunityPool()
+	if poolErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "CommunityPool", "error", poolErr)
+	}
+
+	balances, balancesErr := c.queryModuleBalances()
+	if balancesErr != nil {
+		slog.Error("Failed to query module account balances", "error", balancesErr)
+	}
+
+	// Resolve the display unit of every reported denom.
+	denoms := make(map[string]struct{})
+	for denom := range communityPool {
+		denoms[denom] = struct{}{}
+	}
+	for _, moduleBalances := range balances {
+		for denom := range moduleBalances {
+			denoms[denom] = struct{}{}
+		}
+	}
+	units, unitsErr := queryDisplayUnits(c.grpcClient.Ctx, bankv1beta1.NewQueryClient(c.grpcClient.Conn), maps.Keys(denoms), c.timeout)
+	if unitsErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "error", unitsErr)
+	}
+
+	// Report 'up' metric based on query success
+	upValue := 0.0
+	if poolErr == nil && balancesErr == nil && unitsErr == nil {
+		upValue = 1.0
+	}
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+
+	if unitsErr != nil {
+		collectors.ReportInvalidMetric(ch, c.communityPoolDesc, unitsErr)
+		return
+	}
+
+	if poolErr != nil {
+		collectors.ReportInvalidMetric(ch, c.communityPoolDesc, poolErr)
+	} else {
+		for denom, amount := range communityPool {
+			unit := units[denom]
+			collectors.ReportGaugeMetric(ch, c.communityPoolDesc, toDisplayAmount(amount, unit.exponent), denom, unit.display)
+		}
+	}
+
+	if balancesErr != nil {
+		collectors.ReportInvalidMetric(ch, c.moduleBalanceDesc, balancesErr)
+	} else {
+		for account, moduleBalances := range balances {
+			for denom, amount := range moduleBalances {
+				unit := units[denom]
+				collectors.ReportGaugeMetric(ch, c.moduleBalanceDesc, toDisplayAmount(amount, unit.exponent), account.module, account.address, denom, unit.display)
+			}
+		}
+	}
+}
+
+// moduleAccount identifies a module account.
+type moduleAccount struct {
+	module  string
+	address string
+}
+
+func (c *ModuleAccountsCollector) queryCommunityPool() (map[string]*big.Int, error) {
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	distributionQueryClient := distributionv1beta1.NewQueryClient(c.grpcClient.Conn)
+	resp, err := distributionQueryClient.CommunityPool(ctx, &distributionv1beta1.QueryCommunityPoolRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return truncateDecCoins(resp.Pool)
+}
+
+// queryModuleBalances returns the balances of the configured module accounts.
+// Each query is bounded by the collector timeout.
+func (c *ModuleAccountsCollector) queryModuleBalances() (map[moduleAccount]map[string]*big.Int, error) {
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	addresses, err := queryModuleAccounts(ctx, authv1beta1.NewQueryClient(c.grpcClient.Conn))
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	bankQueryClient := bankv1beta1.NewQueryClient(c.grpcClient.Conn)
+	balances := make(map[moduleAccount]map[string]*big.Int, len(c.modules))
+	for _, module := range c.modules {
+		address, ok := addresses[module]
+		if !ok {
+			slog.Debug("Module account not found", "module", module)
+			continue
+		}
+		moduleBalances, err := queryAllBalances(c.grpcClient.Ctx, bankQueryClient, address, c.timeout)
+		if err != nil {
+			return nil, err
+		}
+		balances[moduleAccount{module: module, address: address}] = moduleBalances
+	}
+	return balances, nil
+}
+
+func init() {
+	RegisterCollectorFactory("module_accounts", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := moduleAccountsOptions{Modules: slices.Clone(defaultModuleAccounts)}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid module_accounts options", "error", err)
+		}
+		return NewModuleAccountsCollector(grpcClient, opts.Modules, cfg.Timeout)
+	})
+}
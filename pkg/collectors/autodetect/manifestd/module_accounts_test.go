@@ -0,0 +1,90 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	distributionv1beta1 "cosmossdk.io/api/cosmos/distribution/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeModuleAccountsNode serves the module accounts and their balances, by address.
+type fakeModuleAccountsNode struct {
+	accounts map[string]string // Addresses by module name
+	balances map[string]string // umfx balances by address
+}
+
+type fakeModuleAuthServer struct {
+	authv1beta1.UnimplementedQueryServer
+	*fakeModuleAccountsNode
+}
+
+func (s fakeModuleAuthServer) ModuleAccounts(context.Context, *authv1beta1.QueryModuleAccountsRequest) (*authv1beta1.QueryModuleAccountsResponse, error) {
+	var accounts []*anypb.Any
+	for name, address := range s.accounts {
+		account, err := anypb.New(&authv1beta1.ModuleAccount{Name: name, BaseAccount: &authv1beta1.BaseAccount{Address: address}})
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return &authv1beta1.QueryModuleAccountsResponse{Accounts: accounts}, nil
+}
+
+type fakeModuleBankServer struct {
+	fakeMetadataServer
+	*fakeModuleAccountsNode
+}
+
+func (s fakeModuleBankServer) AllBalances(_ context.Context, req *bankv1beta1.QueryAllBalancesRequest) (*bankv1beta1.QueryAllBalancesResponse, error) {
+	return &bankv1beta1.QueryAllBalancesResponse{Balances: []*basev1beta1.Coin{{Denom: "umfx", Amount: s.balances[req.Address]}}}, nil
+}
+
+type fakeCommunityPoolServer struct {
+	distributionv1beta1.UnimplementedQueryServer
+}
+
+func (fakeCommunityPoolServer) CommunityPool(context.Context, *distributionv1beta1.QueryCommunityPoolRequest) (*distributionv1beta1.QueryCommunityPoolResponse, error) {
+	return &distributionv1beta1.QueryCommunityPoolResponse{Pool: []*basev1beta1.DecCoin{{Denom: "umfx", Amount: "3000000.900000000000000000"}}}, nil
+}
+
+func TestModuleAccountsCollector(t *testing.T) {
+	node := &fakeModuleAccountsNode{
+		accounts: map[string]string{
+			"distribution":       "manifest1distribution",
+			"fee_collector":      "manifest1feecollector",
+			"bonded_tokens_pool": "manifest1bonded",
+		},
+		balances: map[string]string{
+			"manifest1distribution": "4000000",
+			"manifest1feecollector": "1500000",
+			"manifest1bonded":       "9000000",
+		},
+	}
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		authv1beta1.RegisterQueryServer(server, fakeModuleAuthServer{fakeModuleAccountsNode: node})
+		bankv1beta1.RegisterQueryServer(server, fakeModuleBankServer{fakeModuleAccountsNode: node})
+		distributionv1beta1.RegisterQueryServer(server, fakeCommunityPoolServer{})
+	})
+	// The mint module has no account on chain, and the bonded tokens pool is not configured.
+	got := gatherValues(t, NewModuleAccountsCollector(grpcClient, []string{"distribution", "fee_collector", "mint"}, time.Second))
+
+	want := map[string]float64{
+		"manifest_tokenomics_community_pool_amount{denom=umfx,display=mfx}":                                                            3,
+		"manifest_tokenomics_module_account_balance_amount{address=manifest1distribution,denom=umfx,display=mfx,module=distribution}":  4,
+		"manifest_tokenomics_module_account_balance_amount{address=manifest1feecollector,denom=umfx,display=mfx,module=fee_collector}": 1.5,
+		"manifest_tokenomics_module_accounts_grpc_up":                                                                                  1,
+	}
+	assertValues(t, got, want)
+	if len(got) != len(want) {
+		t.Errorf("series = %v, want only %v", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// NodeStatusCollector collects the sync status, latest block and node information from the Cosmos SDK tendermint service via gRPC.
+type NodeStatusCollector struct {
+	grpcClient         *client.GRPCClient
+	syncingDesc        *prometheus.Desc // Whether the node is catching up
+	blockHeightDesc    *prometheus.Desc // Latest block height
+	blockTimeDesc      *prometheus.Desc // Latest block time
+	sinceLastBlockDesc *prometheus.Desc // Time since the latest block
+	nodeInfoDesc       *prometheus.Desc // Node information
+	upDesc             *prometheus.Desc // gRPC query success
+	timeout            time.Duration
+	initialError       error
+}
+
+// NewNodeStatusCollector creates a new NodeStatusCollector.
+// It requires a gRPC client connection to query the tendermint service.
+func NewNodeStatusCollector(client *client.GRPCClient, timeout time.Duration) *NodeStatusCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	return &NodeStatusCollector{
+		grpcClient:   client,
+		initialError: initialError,
+		timeout:      timeout,
+		syncingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "node", "syncing"),
+			"Whether the node is catching up with the network.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		blockHeightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "node", "latest_block_height"),
+			"Height of the latest block.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		blockTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "node", "latest_block_time"),
+			"Unix timestamp of the latest block.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		sinceLastBlockDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "node", "seconds_since_last_block"),
+			"Seconds elapsed since the latest block.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		nodeInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "node", "info"),
+			"Information about the node.",
+			[]string{"moniker", "network", "node_id", "cometbft_version", "app_name", "app_version", "go_version", "git_commit", "cosmos_sdk_version"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "node", "status_grpc_up"),
+			"Whether the gRPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "GetSyncing, GetLatestBlock, GetNodeInfo"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *NodeStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.syncingDesc
+	ch <- c.blockHeightDesc
+	ch <- c.blockTimeDesc
+	ch <- c.sinceLastBlockDesc
+	ch <- c.nodeInfoDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *NodeStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.syncingDesc, err)
+		collectors.ReportInvalidMetric(ch, c.blockHeightDesc, err)
+		collectors.ReportInvalidMetric(ch, c.nodeInfoDesc, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	tmQueryClient := tmv1beta1.NewServiceClient(c.grpcClient.Conn)
+	syncingResp, syncingErr := tmQueryClient.GetSyncing(ctx, &tmv1beta1.GetSyncingRequest{})
+	if syncingErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetSyncing", "error", syncingErr)
+	}
+
+	header, headerErr := queryLatestBlockHeader(ctx, tmQueryClient)
+	if headerErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetLatestBlock", "error", headerErr)
+	}
+
+	nodeInfoResp, nodeInfoErr := tmQueryClient.GetNodeInfo(ctx, &tmv1beta1.GetNodeInfoRequest{})
+	if nodeInfoErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetNodeInfo", "error", nodeInfoErr)
+	}
+
+	// Report 'up' metric based on query success
+	upValue := 0.0
+	if syncingErr == nil && headerErr == nil && nodeInfoErr == nil {
+		upValue = 1.0
+	}
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+
+	if syncingErr != nil {
+		collectors.ReportInvalidMetric(ch, c.syncingDesc, syncingErr)
+	} else {
+		syncing := 0.0
+		if syncingResp.Syncing {
+			syncing = 1.0
+		}
+		collectors.ReportGaugeMetric(ch, c.syncingDesc, syncing)
+	}
+
+	if headerErr != nil {
+		collectors.ReportInvalidMetric(ch, c.blockHeightDesc, headerErr)
+	} else {
+		collectors.ReportGaugeMetric(ch, c.blockHeightDesc, float64(header.Height))
+		collectors.ReportGaugeMetric(ch, c.blockTimeDesc, float64(header.Time.UnixNano())/1e9)
+		collectors.ReportGaugeMetric(ch, c.sinceLastBlockDesc, time.Since(header.Time).Seconds())
+	}
+
+	if nodeInfoErr != nil {
+		collectors.ReportInvalidMetric(ch, c.nodeInfoDesc, nodeInfoErr)
+	} else {
+		c.collectNodeInfo(ch, nodeInfoResp)
+	}
+}
+
+func (c *NodeStatusCollector) collectNodeInfo(ch chan<- prometheus.Metric, resp *tmv1beta1.GetNodeInfoResponse) {
+	nodeInfo := resp.GetDefaultNodeInfo()
+	appVersion := resp.GetApplicationVersion()
+	if nodeInfo == nil && appVersion == nil {
+		collectors.ReportInvalidMetric(ch, c.nodeInfoDesc, status.Error(codes.Internal, "GetNodeInfo response is empty"))
+		return
+	}
+
+	collectors.ReportGaugeMetric(ch, c.nodeInfoDesc, 1, // Value is 1 to indicate presence/info
+		nodeInfo.GetMoniker(),
+		nodeInfo.GetNetwork(),
+		nodeInfo.GetDefaultNodeId(),
+		nodeInfo.GetVersion(),
+		appVersion.GetAppName(),
+		appVersion.GetVersion(),
+		appVersion.GetGoVersion(),
+		appVersion.GetGitCommit(),
+		appVersion.GetCosmosSdkVersion(),
+	)
+}
+
+func init() {
+	RegisterCollectorFactory("node_status", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		return NewNodeStatusCollector(grpcClient, cfg.Timeout)
+	})
+}
@@ -0,0 +1,111 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeNodeStatusServer serves the tendermint queries used by the node status collector.
+type fakeNodeStatusServer struct {
+	tmv1beta1.UnimplementedServiceServer
+	syncing     bool
+	blockTime   time.Time
+	failLatest  bool // Fail the GetLatestBlock query
+	blockHeight int64
+}
+
+func (s fakeNodeStatusServer) GetSyncing(context.Context, *tmv1beta1.GetSyncingRequest) (*tmv1beta1.GetSyncingResponse, error) {
+	return &tmv1beta1.GetSyncingResponse{Syncing: s.syncing}, nil
+}
+
+func (s fakeNodeStatusServer) GetLatestBlock(context.Context, *tmv1beta1.GetLatestBlockRequest) (*tmv1beta1.GetLatestBlockResponse, error) {
+	if s.failLatest {
+		return nil, status.Error(codes.Unavailable, "node unreachable")
+	}
+	return &tmv1beta1.GetLatestBlockResponse{SdkBlock: &tmv1beta1.Block{Header: &tmv1beta1.Header{
+		Height: s.blockHeight,
+		Time:   timestamppb.New(s.blockTime),
+	}}}, nil
+}
+
+func (fakeNodeStatusServer) GetNodeInfo(context.Context, *tmv1beta1.GetNodeInfoRequest) (*tmv1beta1.GetNodeInfoResponse, error) {
+	return &tmv1beta1.GetNodeInfoResponse{ApplicationVersion: &tmv1beta1.VersionInfo{AppName: "manifestd", Version: "v1.0.0"}}, nil
+}
+
+func TestNodeStatusCollector(t *testing.T) {
+	blockTime := time.Unix(1_700_000_000, 500_000_000)
+	tests := []struct {
+		name    string
+		syncing bool
+		want    float64 // Expected syncing value
+	}{
+		{"caught up", false, 0},
+		{"catching up", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+				tmv1beta1.RegisterServiceServer(server, fakeNodeStatusServer{syncing: tt.syncing, blockHeight: 1234, blockTime: blockTime})
+			})
+			got := gatherValues(t, NewNodeStatusCollector(grpcClient, time.Second))
+
+			assertValues(t, got, map[string]float64{
+				"manifest_node_syncing":             tt.want,
+				"manifest_node_latest_block_height": 1234,
+				"manifest_node_latest_block_time":   1_700_000_000.5,
+				"manifest_node_status_grpc_up":      1,
+				"manifest_node_info{app_name=manifestd,app_version=v1.0.0,cometbft_version=,cosmos_sdk_version=,git_commit=,go_version=,moniker=,network=,node_id=}": 1,
+			})
+			if since := got["manifest_node_seconds_since_last_block"]; since < time.Since(blockTime).Seconds()-60 {
+				t.Errorf("seconds_since_last_block = %v, want the time elapsed since the block", since)
+			}
+		})
+	}
+}
+
+func TestNodeStatusCollectorFailedQuery(t *testing.T) {
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		tmv1beta1.RegisterServiceServer(server, fakeNodeStatusServer{syncing: true, failLatest: true})
+	})
+	collector := NewNodeStatusCollector(grpcClient, time.Second)
+
+	ch := make(chan prometheus.Metric, 16)
+	collector.Collect(ch)
+	close(ch)
+
+	values := make(map[*prometheus.Desc]float64)
+	var invalid []*prometheus.Desc
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			invalid = append(invalid, metric.Desc())
+			continue
+		}
+		values[metric.Desc()] = m.GetGauge().GetValue()
+	}
+	if len(invalid) != 1 || invalid[0] != collector.blockHeightDesc {
+		t.Errorf("invalid metrics = %v, want the latest block height only", invalid)
+	}
+	if up, ok := values[collector.upDesc]; !ok || up != 0 {
+		t.Errorf("status_grpc_up = %v (present: %v), want 0", up, ok)
+	}
+	if syncing, ok := values[collector.syncingDesc]; !ok || syncing != 1 {
+		t.Errorf("syncing = %v (present: %v), want 1", syncing, ok)
+	}
+	if _, ok := values[collector.blockTimeDesc]; ok {
+		t.Error("latest block time reported for a failed query")
+	}
+}
@@ -1,21 +1,19 @@
 package manifestd
 
 import (
-	"maps"
-	"slices"
-
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
 	"github.com/manifest-network/manifest-node-exporter/pkg/utils"
 )
 
 // ManifestdCollectorFactory is a function type that creates a prometheus.Collector.
-// It takes a gRPC client and optional extra parameters and returns a prometheus.Collector.
+// It takes a gRPC client and the collector configuration and returns a prometheus.Collector.
 // This is used to register different types of collectors for the manifestd process.
 // The gRPC client is found at runtime by the autodetection process.
-// The extra parameters can be used to pass additional configuration or context to the collector factory.
-type ManifestdCollectorFactory = func(grpcClient *client.GRPCClient, extra ...interface{}) prometheus.Collector
+// The configuration is read from the `collectors.<name>` section of the configuration file, where name is the registration key.
+type ManifestdCollectorFactory = func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector
 
 // manifestdCollectorRegistry is a registry for all manifestd collector factories.
 var manifestdCollectorRegistry = utils.NewRegistry[ManifestdCollectorFactory]()
@@ -25,7 +23,25 @@ func RegisterCollectorFactory(name string, factory ManifestdCollectorFactory) {
 	manifestdCollectorRegistry.Register(name, factory)
 }
 
-// GetAllCollectorFactories retrieves all the collector factories from the registry.
-func GetAllCollectorFactories() []ManifestdCollectorFactory {
-	return slices.Collect(maps.Values(manifestdCollectorRegistry.GetAll()))
+// GetAllCollectorFactories retrieves all the collector factories from the registry, keyed by name.
+func GetAllCollectorFactories() map[string]ManifestdCollectorFactory {
+	return manifestdCollectorRegistry.GetAll()
+}
+
+// ManifestdRPCCollectorFactory is a function type that creates a prometheus.Collector from a CometBFT RPC client.
+// The RPC client is found at runtime by the autodetection process, and RPC collectors are skipped when it is not found.
+// The configuration is read from the `collectors.<name>` section of the configuration file, where name is the registration key.
+type ManifestdRPCCollectorFactory = func(rpcClient *client.RPCClient, cfg collectors.CollectorConfig) prometheus.Collector
+
+// manifestdRPCCollectorRegistry is a registry for all manifestd RPC collector factories.
+var manifestdRPCCollectorRegistry = utils.NewRegistry[ManifestdRPCCollectorFactory]()
+
+// RegisterRPCCollectorFactory registers a new collector factory with the manifestd RPC collector registry.
+func RegisterRPCCollectorFactory(name string, factory ManifestdRPCCollectorFactory) {
+	manifestdRPCCollectorRegistry.Register(name, factory)
+}
+
+// GetAllRPCCollectorFactories retrieves all the RPC collector factories from the registry, keyed by name.
+func GetAllRPCCollectorFactories() map[string]ManifestdRPCCollectorFactory {
+	return manifestdRPCCollectorRegistry.GetAll()
 }
@@ -0,0 +1,330 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	slashingv1beta1 "cosmossdk.io/api/cosmos/slashing/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect"
+)
+
+// signingInfoConcurrency is the maximum number of signing infos queried concurrently.
+const signingInfoConcurrency = 8
+
+// signingInfoOptions holds the collector-specific options of the signing info collector.
+type signingInfoOptions struct {
+	ConsensusAddresses []string `mapstructure:"consensus_addresses"` // Consensus addresses of the tracked validators
+	NodeHome           string   `mapstructure:"node_home"`           // Node home holding the validator key, when no address is configured
+}
+
+// SigningInfoCollector collects the signing information of tracked validators from the Cosmos SDK slashing module via gRPC.
+type SigningInfoCollector struct {
+	grpcClient          *client.GRPCClient
+	missedBlocksDesc    *prometheus.Desc // Missed blocks in the current window
+	jailedUntilDesc     *prometheus.Desc // Jailed until timestamp
+	tombstonedDesc      *prometheus.Desc // Tombstoned flag
+	startHeightDesc     *prometheus.Desc // Signing start height
+	signedWindowDesc    *prometheus.Desc // Signed blocks window
+	minSignedWindowDesc *prometheus.Desc // Minimum signed ratio per window
+	addressValidDesc    *prometheus.Desc // Whether the configured address is valid
+	querySuccessDesc    *prometheus.Desc // Per-validator query success
+	upDesc              *prometheus.Desc // gRPC query success
+	consensusAddresses  []string
+	invalidAddresses    []string
+	timeout             time.Duration
+	initialError        error
+}
+
+// NewSigningInfoCollector creates a new SigningInfoCollector.
+// It requires a gRPC client connection to query the slashing module, and at least one consensus address to track.
+// Invalid consensus addresses are reported as such and never queried.
+func NewSigningInfoCollector(client *client.GRPCClient, consensusAddresses []string, timeout time.Duration) *SigningInfoCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	} else if len(consensusAddresses) == 0 {
+		initialError = status.Error(codes.InvalidArgument, "no consensus address to track")
+	}
+
+	var valid, invalid []string
+	for _, address := range consensusAddresses {
+		if err := validateAddress(address, consensusPrefix); err != nil {
+			slog.Error("Invalid consensus address", "error", err)
+			invalid = append(invalid, address)
+			continue
+		}
+		valid = append(valid, address)
+	}
+
+	return &SigningInfoCollector{
+		grpcClient:         client,
+		initialError:       initialError,
+		consensusAddresses: valid,
+		invalidAddresses:   invalid,
+		timeout:            timeout,
+		missedBlocksDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "missed_blocks"),
+			"Number of blocks missed by the validator in the current signed blocks window.",
+			[]string{"consensus_address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		jailedUntilDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "jailed_until"),
+			"Unix timestamp until which the validator is jailed.",
+			[]string{"consensus_address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		tombstonedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "tombstoned"),
+			"Whether the validator is tombstoned.",
+			[]string{"consensus_address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		startHeightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "start_height"),
+			"Height at which the validator started signing.",
+			[]string{"consensus_address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		signedWindowDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "signed_blocks_window"),
+			"Number of blocks over which missed blocks are counted.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		minSignedWindowDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "min_signed_per_window"),
+			"Minimum ratio of blocks a validator must sign per window to avoid being jailed.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		addressValidDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "address_valid"),
+			"Whether the configured consensus address of a tracked validator is a valid bech32 address.",
+			[]string{"consensus_address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		querySuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "query_success"),
+			"Whether the signing info of a tracked validator could be queried.",
+			[]string{"consensus_address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "slashing", "grpc_up"),
+			"Whether the gRPC queries were successful, i.e. the params and the signing info of at least one validator.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "SigningInfo, Params"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *SigningInfoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.missedBlocksDesc
+	ch <- c.jailedUntilDesc
+	ch <- c.tombstonedDesc
+	ch <- c.startHeightDesc
+	ch <- c.signedWindowDesc
+	ch <- c.minSignedWindowDesc
+	ch <- c.addressValidDesc
+	ch <- c.querySuccessDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *SigningInfoCollector) Collect(ch chan<- prometheus.Metric) {
+	// Invalid addresses are reported without failing the scrape.
+	for _, address := range c.invalidAddresses {
+		collectors.ReportGaugeMetric(ch, c.addressValidDesc, 0, address)
+	}
+	for _, address := range c.consensusAddresses {
+		collectors.ReportGaugeMetric(ch, c.addressValidDesc, 1, address)
+	}
+
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.missedBlocksDesc, err)
+		collectors.ReportInvalidMetric(ch, c.signedWindowDesc, err)
+		return
+	}
+
+	slashingQueryClient := slashingv1beta1.NewQueryClient(c.grpcClient.Conn)
+	upValue := 1.0
+
+	paramsCtx, paramsCancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer paramsCancel()
+
+	paramsResp, err := slashingQueryClient.Params(paramsCtx, &slashingv1beta1.QueryParamsRequest{})
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "Params", "error", err)
+		collectors.ReportInvalidMetric(ch, c.signedWindowDesc, err)
+		upValue = 0
+	} else {
+		c.collectParams(ch, paramsResp.GetParams())
+	}
+
+	// Query every validator with its own timeout. A failing validator is reported by its query success
+	// and does not prevent reporting the others.
+	infos := make([]*slashingv1beta1.ValidatorSigningInfo, len(c.consensusAddresses))
+	failed := make([]bool, len(c.consensusAddresses))
+	var eg errgroup.Group
+	eg.SetLimit(signingInfoConcurrency)
+	for i, address := range c.consensusAddresses {
+		eg.Go(func() error {
+			info, err := c.querySigningInfo(slashingQueryClient, address)
+			if err != nil {
+				slog.Error("Failed to query via gRPC", "query", "SigningInfo", "consensus_address", address, "error", err)
+				failed[i] = true
+				return nil
+			}
+			infos[i] = info
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	failures := 0
+	for i, address := range c.consensusAddresses {
+		if failed[i] {
+			failures++
+			collectors.ReportGaugeMetric(ch, c.querySuccessDesc, 0, address)
+			continue
+		}
+		collectors.ReportGaugeMetric(ch, c.querySuccessDesc, 1, address)
+		if infos[i] != nil {
+			c.collectSigningInfo(ch, address, infos[i])
+		}
+	}
+	if failures > 0 && failures == len(c.consensusAddresses) {
+		upValue = 0
+	}
+
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+// querySigningInfo returns the signing info of a validator, or nil if the validator has none.
+func (c *SigningInfoCollector) querySigningInfo(slashingQueryClient slashingv1beta1.QueryClient, address string) (*slashingv1beta1.ValidatorSigningInfo, error) {
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	resp, err := slashingQueryClient.SigningInfo(ctx, &slashingv1beta1.QuerySigningInfoRequest{ConsAddress: address})
+	if status.Code(err) == codes.NotFound {
+		// The validator has never been part of the active set.
+		slog.Warn("No signing info for validator", "consensus_address", address)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetValSigningInfo() == nil {
+		return nil, status.Error(codes.Internal, "signing info response is nil")
+	}
+	return resp.GetValSigningInfo(), nil
+}
+
+func (c *SigningInfoCollector) collectParams(ch chan<- prometheus.Metric, params *slashingv1beta1.Params) {
+	if params == nil {
+		collectors.ReportInvalidMetric(ch, c.signedWindowDesc, status.Error(codes.Internal, "slashing params response is nil"))
+		return
+	}
+
+	collectors.ReportGaugeMetric(ch, c.signedWindowDesc, float64(params.SignedBlocksWindow))
+
+	minSigned, err := parseLegacyDec(string(params.MinSignedPerWindow))
+	if err != nil {
+		collectors.ReportInvalidMetric(ch, c.minSignedWindowDesc, err)
+		return
+	}
+	minSignedValue, _ := minSigned.Float64()
+	collectors.ReportGaugeMetric(ch, c.minSignedWindowDesc, minSignedValue)
+}
+
+func (c *SigningInfoCollector) collectSigningInfo(ch chan<- prometheus.Metric, address string, info *slashingv1beta1.ValidatorSigningInfo) {
+	if info == nil {
+		collectors.ReportInvalidMetric(ch, c.missedBlocksDesc, status.Error(codes.Internal, "signing info response is nil"))
+		return
+	}
+
+	tombstoned := 0.0
+	if info.Tombstoned {
+		tombstoned = 1.0
+	}
+	// A validator that was never jailed has a zero jailed-until time.
+	jailedUntil := 0.0
+	if t := info.GetJailedUntil().AsTime(); t.Unix() > 0 {
+		jailedUntil = float64(t.Unix())
+	}
+
+	collectors.ReportGaugeMetric(ch, c.missedBlocksDesc, float64(info.MissedBlocksCounter), address)
+	collectors.ReportGaugeMetric(ch, c.jailedUntilDesc, jailedUntil, address)
+	collectors.ReportGaugeMetric(ch, c.tombstonedDesc, tombstoned, address)
+	collectors.ReportGaugeMetric(ch, c.startHeightDesc, float64(info.StartHeight), address)
+}
+
+// trackedConsensusAddresses returns the configured consensus addresses without duplicates,
+// or the address of the validator key found in the home of the detected node.
+func trackedConsensusAddresses(opts signingInfoOptions, processInfo *autodetect.ProcessInfo) ([]string, error) {
+	if len(opts.ConsensusAddresses) > 0 {
+		return dedupeConsensusAddresses(opts.ConsensusAddresses), nil
+	}
+
+	nodeHome := opts.NodeHome
+	if nodeHome == "" {
+		var err error
+		if nodeHome, err = resolveNodeHome(processInfo); err != nil {
+			return nil, err
+		}
+	}
+	address, err := consensusAddressFromKeyFile(nodeHome)
+	if err != nil {
+		return nil, err
+	}
+	return []string{address}, nil
+}
+
+// dedupeConsensusAddresses drops, with an error log, the duplicate consensus addresses.
+// The first occurrence of a duplicate is kept.
+func dedupeConsensusAddresses(addresses []string) []string {
+	var result []string
+	seen := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		if _, ok := seen[address]; ok {
+			slog.Error("Skipping duplicate consensus address", "address", address)
+			continue
+		}
+		seen[address] = struct{}{}
+		result = append(result, address)
+	}
+	return result
+}
+
+func init() {
+	// Only validator nodes have signing info to report.
+	collectors.RegisterOptInCollector("signing_info")
+	RegisterCollectorFactory("signing_info", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		var opts signingInfoOptions
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid signing_info options", "error", err)
+		}
+		addresses, err := trackedConsensusAddresses(opts, processInfoFromContext(grpcClient.Ctx))
+		if err != nil {
+			slog.Error("Failed to find the consensus address of the validator", "error", err)
+		}
+		return NewSigningInfoCollector(grpcClient, addresses, cfg.Timeout)
+	})
+}
@@ -0,0 +1,163 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	slashingv1beta1 "cosmossdk.io/api/cosmos/slashing/v1beta1"
+	"github.com/cosmos/btcutil/bech32"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors/autodetect"
+)
+
+func TestNewSigningInfoCollectorValidatesAddresses(t *testing.T) {
+	valid, account := testAddress(t, consensusPrefix, 0), testAddress(t, bech32Prefix, 0)
+
+	grpcClient, err := client.NewGRPCClient(context.Background(), "127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer grpcClient.Close()
+
+	tests := []struct {
+		name        string
+		addresses   []string
+		wantValid   int
+		wantInvalid int
+		wantErr     bool
+	}{
+		{"valid consensus address", []string{valid}, 1, 0, false},
+		{"account address", []string{valid, account}, 1, 1, false},
+		{"malformed address", []string{"manifestvalcons1invalid"}, 0, 1, false},
+		{"no address", nil, 0, 0, true},
+	}
+	for _, tt := range tests {
+		c := NewSigningInfoCollector(grpcClient, tt.addresses, time.Second)
+		if (c.initialError != nil) != tt.wantErr {
+			t.Errorf("%s: initialError = %v, wantErr %v", tt.name, c.initialError, tt.wantErr)
+		}
+		if len(c.consensusAddresses) != tt.wantValid || len(c.invalidAddresses) != tt.wantInvalid {
+			t.Errorf("%s: valid, invalid = %d, %d, want %d, %d", tt.name,
+				len(c.consensusAddresses), len(c.invalidAddresses), tt.wantValid, tt.wantInvalid)
+		}
+	}
+}
+
+// fakeSlashingServer serves the slashing queries, failing the signing info of the addresses in failing.
+type fakeSlashingServer struct {
+	slashingv1beta1.UnimplementedQueryServer
+	failing map[string]bool
+}
+
+func (fakeSlashingServer) Params(context.Context, *slashingv1beta1.QueryParamsRequest) (*slashingv1beta1.QueryParamsResponse, error) {
+	return &slashingv1beta1.QueryParamsResponse{Params: &slashingv1beta1.Params{
+		SignedBlocksWindow: 100,
+		MinSignedPerWindow: []byte("0.500000000000000000"),
+	}}, nil
+}
+
+func (s fakeSlashingServer) SigningInfo(_ context.Context, req *slashingv1beta1.QuerySigningInfoRequest) (*slashingv1beta1.QuerySigningInfoResponse, error) {
+	if s.failing[req.ConsAddress] {
+		return nil, status.Error(codes.Unavailable, "node unreachable")
+	}
+	return &slashingv1beta1.QuerySigningInfoResponse{ValSigningInfo: &slashingv1beta1.ValidatorSigningInfo{
+		Address:             req.ConsAddress,
+		MissedBlocksCounter: 3,
+	}}, nil
+}
+
+func TestSigningInfoCollectorPartialFailure(t *testing.T) {
+	ok, failing, invalid := testAddress(t, consensusPrefix, 1), testAddress(t, consensusPrefix, 2), "manifestvalcons1invalid"
+
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		slashingv1beta1.RegisterQueryServer(server, fakeSlashingServer{failing: map[string]bool{failing: true}})
+	})
+	got := gatherValues(t, NewSigningInfoCollector(grpcClient, []string{ok, failing, invalid}, time.Second))
+
+	want := map[string]float64{
+		"manifest_slashing_missed_blocks{consensus_address=" + ok + "}":      3,
+		"manifest_slashing_query_success{consensus_address=" + ok + "}":      1,
+		"manifest_slashing_query_success{consensus_address=" + failing + "}": 0,
+		"manifest_slashing_address_valid{consensus_address=" + ok + "}":      1,
+		"manifest_slashing_address_valid{consensus_address=" + invalid + "}": 0,
+		"manifest_slashing_grpc_up":                                          1,
+	}
+	assertValues(t, got, want)
+	if _, ok := got["manifest_slashing_missed_blocks{consensus_address="+failing+"}"]; ok {
+		t.Error("missed blocks reported for the failing validator")
+	}
+}
+
+// writeValidatorKey writes a validator key for the address at path, creating its directory.
+func writeValidatorKey(t *testing.T, path string, address []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	key := fmt.Sprintf(`{"address": %q}`, strings.ToUpper(hex.EncodeToString(address)))
+	if err := os.WriteFile(path, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrackedConsensusAddresses(t *testing.T) {
+	first, second := testAddress(t, consensusPrefix, 1), testAddress(t, consensusPrefix, 2)
+	_, raw, err := bech32.DecodeToBase256(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaultHome, customHome, absoluteHome := t.TempDir(), t.TempDir(), t.TempDir()
+	writeValidatorKey(t, filepath.Join(defaultHome, "config", "priv_validator_key.json"), raw)
+	writeValidatorKey(t, filepath.Join(customHome, "keys", "validator.json"), raw)
+	absoluteKey := filepath.Join(t.TempDir(), "validator.json")
+	writeValidatorKey(t, absoluteKey, raw)
+	for home, keyFile := range map[string]string{customHome: "keys/validator.json", absoluteHome: absoluteKey} {
+		if err := os.MkdirAll(filepath.Join(home, "config"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		config := fmt.Sprintf("proxy_app = \"tcp://127.0.0.1:26658\"\npriv_validator_key_file = %q\n\n[rpc]\nladdr = \"tcp://127.0.0.1:26657\"\n", keyFile)
+		if err := os.WriteFile(filepath.Join(home, "config", "config.toml"), []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		opts        signingInfoOptions
+		processInfo *autodetect.ProcessInfo
+		want        []string
+		wantErr     bool
+	}{
+		{"configured addresses", signingInfoOptions{ConsensusAddresses: []string{first, second, first}}, nil, []string{first, second}, false},
+		{"default key file", signingInfoOptions{NodeHome: defaultHome}, nil, []string{first}, false},
+		{"relative key file", signingInfoOptions{NodeHome: customHome}, nil, []string{first}, false},
+		{"absolute key file", signingInfoOptions{NodeHome: absoluteHome}, nil, []string{first}, false},
+		{"remote node", signingInfoOptions{}, &autodetect.ProcessInfo{Address: "10.0.0.1", Port: 9090}, nil, true},
+		{"no detected node", signingInfoOptions{}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := trackedConsensusAddresses(tt.opts, tt.processInfo)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: addresses = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
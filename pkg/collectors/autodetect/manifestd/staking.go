@@ -0,0 +1,64 @@
+package manifestd
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"cosmossdk.io/math"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// queryAllValidators returns every validator, whatever its status, following pagination to completion.
+// Each page request is bounded by the given timeout.
+func queryAllValidators(ctx context.Context, stakingQueryClient stakingv1beta1.QueryClient, timeout time.Duration) ([]*stakingv1beta1.Validator, error) {
+	var validators []*stakingv1beta1.Validator
+	err := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := stakingQueryClient.Validators(pageCtx, &stakingv1beta1.QueryValidatorsRequest{Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		validators = append(validators, resp.Validators...)
+		return resp.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
+
+// parseLegacyDec parses a decimal returned by the gRPC API.
+// Decimals are usually encoded as integers scaled by 10^18, but some nodes return a decimal string.
+func parseLegacyDec(value string) (math.LegacyDec, error) {
+	if strings.Contains(value, ".") {
+		return math.LegacyNewDecFromStr(value)
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return math.LegacyDec{}, status.Errorf(codes.Internal, "invalid decimal: %s", value)
+	}
+	return math.LegacyNewDecFromBigIntWithPrec(amount, math.LegacyPrecision), nil
+}
+
+// bondStatusLabel returns a short label for a validator bond status.
+func bondStatusLabel(bondStatus stakingv1beta1.BondStatus) string {
+	switch bondStatus {
+	case stakingv1beta1.BondStatus_BOND_STATUS_BONDED:
+		return "bonded"
+	case stakingv1beta1.BondStatus_BOND_STATUS_UNBONDING:
+		return "unbonding"
+	case stakingv1beta1.BondStatus_BOND_STATUS_UNBONDED:
+		return "unbonded"
+	default:
+		return "unspecified"
+	}
+}
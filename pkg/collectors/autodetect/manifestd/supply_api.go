@@ -0,0 +1,209 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SupplySnapshot holds the supply figures served by the supply API, in base units.
+type SupplySnapshot struct {
+	Denom            string
+	Display          string
+	Exponent         uint32
+	Height           int64 // Block height of the figures
+	Total            *big.Int
+	Excluded         *big.Int
+	Circulating      *big.Int
+	MissingAddresses int // Excluded addresses whose balance could not be queried, missing from the excluded supply
+}
+
+// supplyAmount is the JSON representation of a supply figure.
+type supplyAmount struct {
+	Denom            string `json:"denom"`
+	DisplayDenom     string `json:"display_denom"`
+	Amount           string `json:"amount"`            // Display units
+	BaseAmount       string `json:"base_amount"`       // Base units
+	Height           int64  `json:"height"`            // Block height of the figure
+	MissingAddresses int    `json:"missing_addresses"` // Excluded addresses missing from the excluded supply
+}
+
+// supplySource computes the supply figures. It is implemented by the excluded supply collector.
+type supplySource interface {
+	Supply(ctx context.Context) (*SupplySnapshot, error)
+}
+
+// currentSupplySource is the source of the most recently detected manifestd process.
+var currentSupplySource atomic.Pointer[supplySource]
+
+// setSupplySource makes the supply API use the given source until ctx is done,
+// i.e. until the process backing the source goes away, unless a newer source was set in between.
+func setSupplySource(ctx context.Context, source supplySource) {
+	current := &source
+	currentSupplySource.Store(current)
+	go func() {
+		<-ctx.Done()
+		currentSupplySource.CompareAndSwap(current, nil)
+	}()
+}
+
+func newSupplySnapshot(denom, display string, exponent uint32, height int64, total, excluded *big.Int) *SupplySnapshot {
+	circulating := new(big.Int).Sub(total, excluded)
+	if circulating.Sign() < 0 {
+		slog.Warn("Excluded supply exceeds total supply", "denom", denom, "total", total.String(), "excluded", excluded.String())
+		circulating.SetInt64(0)
+	}
+	return &SupplySnapshot{
+		Denom:       denom,
+		Display:     display,
+		Exponent:    exponent,
+		Height:      height,
+		Total:       total,
+		Excluded:    excluded,
+		Circulating: circulating,
+	}
+}
+
+// SupplyHandler serves the total, excluded and circulating supply in display units,
+// as expected by market data aggregators:
+//
+//	/supply/total
+//	/supply/excluded
+//	/supply/circulating
+//
+// Values are returned as plain text, or as JSON with `?format=json` or an `Accept: application/json` header.
+// When the balance of some excluded addresses could not be queried, the JSON response reports them in
+// `missing_addresses`, and the plain text excluded and circulating supplies are unavailable.
+// Figures are computed on demand and cached for the configured TTL.
+// Concurrent requests share a single computation, which runs without holding the cache lock.
+type SupplyHandler struct {
+	cacheTTL time.Duration
+	timeout  time.Duration
+	group    singleflight.Group
+
+	mu        sync.Mutex
+	snapshot  *SupplySnapshot
+	fetchedAt time.Time
+}
+
+// NewSupplyHandler creates a new SupplyHandler.
+// Each computation is bounded by the given timeout.
+func NewSupplyHandler(cacheTTL, timeout time.Duration) *SupplyHandler {
+	return &SupplyHandler{
+		cacheTTL: cacheTTL,
+		timeout:  timeout,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *SupplyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	kind := strings.TrimPrefix(r.URL.Path, "/supply/")
+	if kind != "total" && kind != "excluded" && kind != "circulating" {
+		http.NotFound(w, r)
+		return
+	}
+
+	snapshot, err := h.supply(r.Context())
+	if err != nil {
+		slog.Error("Failed to compute supply", "error", err)
+		http.Error(w, "supply is currently unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var amount *big.Int
+	switch kind {
+	case "total":
+		amount = snapshot.Total
+	case "excluded":
+		amount = snapshot.Excluded
+	case "circulating":
+		amount = snapshot.Circulating
+	}
+
+	displayAmount := formatDisplayAmount(amount, snapshot.Exponent)
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(supplyAmount{
+			Denom:            snapshot.Denom,
+			DisplayDenom:     snapshot.Display,
+			Amount:           displayAmount,
+			BaseAmount:       amount.String(),
+			Height:           snapshot.Height,
+			MissingAddresses: snapshot.MissingAddresses,
+		}); err != nil {
+			slog.Error("Failed to write supply response", "error", err)
+		}
+		return
+	}
+
+	// Plain text clients cannot tell an incomplete figure from a complete one.
+	if kind != "total" && snapshot.MissingAddresses > 0 {
+		slog.Warn("Excluded supply is missing addresses", "kind", kind, "missing", snapshot.MissingAddresses)
+		http.Error(w, "supply is currently unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write([]byte(displayAmount)); err != nil {
+		slog.Error("Failed to write supply response", "error", err)
+	}
+}
+
+// supply returns the cached snapshot, or computes a new one if it expired.
+// The computation is shared by the concurrent requests and is not cancelled when the request that started it is.
+func (h *SupplyHandler) supply(ctx context.Context) (*SupplySnapshot, error) {
+	h.mu.Lock()
+	snapshot, fetchedAt := h.snapshot, h.fetchedAt
+	h.mu.Unlock()
+	if snapshot != nil && time.Since(fetchedAt) < h.cacheTTL {
+		return snapshot, nil
+	}
+
+	result := h.group.DoChan("supply", func() (interface{}, error) {
+		source := currentSupplySource.Load()
+		if source == nil {
+			return nil, status.Error(codes.Unavailable, "no manifestd process detected")
+		}
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+		defer cancel()
+
+		snapshot, err := (*source).Supply(ctx)
+		if err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		h.snapshot = snapshot
+		h.fetchedAt = time.Now()
+		h.mu.Unlock()
+		return snapshot, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*SupplySnapshot), nil
+	}
+}
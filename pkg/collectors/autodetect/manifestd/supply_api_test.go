@@ -0,0 +1,191 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeSupplySource struct{}
+
+func (fakeSupplySource) Supply(context.Context) (*SupplySnapshot, error) {
+	return newSupplySnapshot("umfx", "mfx", 6, 42, big.NewInt(3_000_000), big.NewInt(1_000_000)), nil
+}
+
+func TestSupplyHandlerSourceLifetime(t *testing.T) {
+	t.Cleanup(func() { currentSupplySource.Store(nil) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	setSupplySource(ctx, fakeSupplySource{})
+	handler := NewSupplyHandler(0, time.Second)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/supply/circulating", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "2" {
+		t.Fatalf("circulating = %d %q, want 200 \"2\"", rec.Code, rec.Body.String())
+	}
+
+	// The process went away: the source is cleared.
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for currentSupplySource.Load() != nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/supply/circulating", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestSetSupplySourceKeepsNewerSource(t *testing.T) {
+	t.Cleanup(func() { currentSupplySource.Store(nil) })
+
+	oldCtx, oldCancel := context.WithCancel(context.Background())
+	setSupplySource(oldCtx, fakeSupplySource{})
+	newCtx, newCancel := context.WithCancel(context.Background())
+	defer newCancel()
+	setSupplySource(newCtx, fakeSupplySource{})
+	current := currentSupplySource.Load()
+
+	oldCancel()
+	time.Sleep(10 * time.Millisecond)
+	if currentSupplySource.Load() != current {
+		t.Error("cancelling a replaced source cleared the current source")
+	}
+}
+
+// slowSupplySource counts its computations, which last until release is closed.
+type slowSupplySource struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowSupplySource) Supply(ctx context.Context) (*SupplySnapshot, error) {
+	s.calls.Add(1)
+	<-s.release
+	return fakeSupplySource{}.Supply(ctx)
+}
+
+func TestSupplyHandlerSharesComputation(t *testing.T) {
+	t.Cleanup(func() { currentSupplySource.Store(nil) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &slowSupplySource{release: make(chan struct{})}
+	setSupplySource(ctx, source)
+	handler := NewSupplyHandler(time.Minute, time.Second)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/supply/total", nil))
+			codes[i] = rec.Code
+		}()
+	}
+
+	// A cancelled request does not wait for the computation.
+	requestCtx, requestCancel := context.WithCancel(context.Background())
+	requestCancel()
+	if _, err := handler.supply(requestCtx); err == nil {
+		t.Error("supply() with a cancelled request succeeded while the computation is running")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+
+	if calls := source.calls.Load(); calls != 1 {
+		t.Errorf("computations = %d, want 1", calls)
+	}
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+}
+
+// partialSupplySource serves a snapshot missing the balance of an excluded address.
+type partialSupplySource struct{}
+
+func (partialSupplySource) Supply(ctx context.Context) (*SupplySnapshot, error) {
+	snapshot, err := fakeSupplySource{}.Supply(ctx)
+	snapshot.MissingAddresses = 1
+	return snapshot, err
+}
+
+func TestSupplyHandlerMissingAddresses(t *testing.T) {
+	t.Cleanup(func() { currentSupplySource.Store(nil) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	setSupplySource(ctx, partialSupplySource{})
+	handler := NewSupplyHandler(0, time.Second)
+
+	tests := []struct {
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{"/supply/total", http.StatusOK, "3"},
+		{"/supply/excluded", http.StatusServiceUnavailable, ""},
+		{"/supply/circulating", http.StatusServiceUnavailable, ""},
+		{"/supply/circulating?format=json", http.StatusOK, `{"denom":"umfx","display_denom":"mfx","amount":"2","base_amount":"2000000","height":42,"missing_addresses":1}` + "\n"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s status = %d, want %d", tt.target, rec.Code, tt.wantCode)
+		}
+		if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+			t.Errorf("%s body = %q, want %q", tt.target, rec.Body.String(), tt.wantBody)
+		}
+	}
+}
+
+func TestExcludedSupplyCollectorSupplyPartial(t *testing.T) {
+	ok, failing := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2)
+	node := &fakeSupplyNode{balances: map[string]string{ok: "100"}, supply: "1000"}
+	c := newFakeSupplyCollector(t, node, []string{ok, failing},
+		balanceQueryOptions{Concurrency: 2, Retries: 0, RetryBackoff: time.Millisecond, Policy: partialPolicy, MaxMissingRatio: 1})
+
+	snapshot, err := c.Supply(context.Background())
+	if err != nil {
+		t.Fatalf("Supply() error = %v", err)
+	}
+	if snapshot.MissingAddresses != 1 {
+		t.Errorf("missing addresses = %d, want 1", snapshot.MissingAddresses)
+	}
+	if snapshot.Height != 42 || snapshot.Circulating.String() != "900" {
+		t.Errorf("height, circulating = %d, %s, want 42, 900", snapshot.Height, snapshot.Circulating)
+	}
+}
+
+func TestExcludedSupplyCollectorSupplyWithoutBalances(t *testing.T) {
+	failing := testAddress(t, bech32Prefix, 2)
+	node := &fakeSupplyNode{supply: "1000"}
+	c := newFakeSupplyCollector(t, node, []string{failing},
+		balanceQueryOptions{Concurrency: 2, Retries: 0, RetryBackoff: time.Millisecond, Policy: partialPolicy, MaxMissingRatio: 1})
+
+	// The supply API answers 503 Service Unavailable rather than the total supply as circulating supply.
+	if snapshot, err := c.Supply(context.Background()); status.Code(err) != codes.Unavailable {
+		t.Errorf("Supply() = %+v, %v, want an unavailable error", snapshot, err)
+	}
+}
@@ -0,0 +1,77 @@
+package manifestd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	typesv1 "cosmossdk.io/api/tendermint/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// blockHeightMetadataKey is the gRPC metadata key selecting the height at which the Cosmos SDK serves a query.
+const blockHeightMetadataKey = "x-cosmos-block-height"
+
+// blockHeader holds the header fields of a block used by the collectors.
+type blockHeader struct {
+	Height int64
+	Time   time.Time
+}
+
+// queryLatestBlockHeader returns the height and time of the latest block.
+// The SDK block is preferred, falling back to the deprecated CometBFT block on older nodes.
+func queryLatestBlockHeader(ctx context.Context, tmQueryClient tmv1beta1.ServiceClient) (*blockHeader, error) {
+	resp, err := tmQueryClient.GetLatestBlock(ctx, &tmv1beta1.GetLatestBlockRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return headerOf(resp)
+}
+
+// queryBlockHeader returns the height and time of the block at the given height.
+func queryBlockHeader(ctx context.Context, tmQueryClient tmv1beta1.ServiceClient, height int64) (*blockHeader, error) {
+	resp, err := tmQueryClient.GetBlockByHeight(ctx, &tmv1beta1.GetBlockByHeightRequest{Height: height})
+	if err != nil {
+		return nil, err
+	}
+	return headerOf(resp)
+}
+
+// blockResponse is implemented by the responses of the block queries.
+type blockResponse interface {
+	GetSdkBlock() *tmv1beta1.Block
+	GetBlock() *typesv1.Block
+}
+
+// headerOf extracts the header of a block response.
+// The SDK block is preferred, falling back to the deprecated CometBFT block on older nodes.
+func headerOf(resp blockResponse) (*blockHeader, error) {
+	if header := resp.GetSdkBlock().GetHeader(); header != nil {
+		return &blockHeader{Height: header.Height, Time: header.GetTime().AsTime()}, nil
+	}
+	if header := resp.GetBlock().GetHeader(); header != nil {
+		return &blockHeader{Height: header.Height, Time: header.GetTime().AsTime()}, nil
+	}
+	return nil, status.Error(codes.Internal, "block response has no block header")
+}
+
+// withBlockHeight returns a context whose gRPC queries are served at the given height.
+func withBlockHeight(ctx context.Context, height int64) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, blockHeightMetadataKey, strconv.FormatInt(height, 10))
+}
+
+// pinLatestHeight queries the latest block header and returns a context whose gRPC queries are served at its height,
+// so that figures derived from several queries are consistent.
+func pinLatestHeight(ctx context.Context, tmQueryClient tmv1beta1.ServiceClient, timeout time.Duration) (context.Context, *blockHeader, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	latest, err := queryLatestBlockHeader(queryCtx, tmQueryClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	return withBlockHeight(ctx, latest.Height), latest, nil
+}
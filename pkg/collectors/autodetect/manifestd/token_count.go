@@ -4,7 +4,9 @@
 package manifestd
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
 	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
@@ -21,12 +23,13 @@ type TokenCountCollector struct {
 	grpcClient     *client.GRPCClient
 	tokenCountDesc *prometheus.Desc // Token count
 	upDesc         *prometheus.Desc // gRPC query success
+	timeout        time.Duration
 	initialError   error
 }
 
 // NewTokenCountCollector creates a new TokenCountCollector.
 // It requires a gRPC client connection to query the bank module.
-func NewTokenCountCollector(client *client.GRPCClient) *TokenCountCollector {
+func NewTokenCountCollector(client *client.GRPCClient, timeout time.Duration) *TokenCountCollector {
 	var initialError error
 	if client == nil {
 		initialError = status.Error(codes.Internal, "gRPC client is nil")
@@ -37,6 +40,7 @@ func NewTokenCountCollector(client *client.GRPCClient) *TokenCountCollector {
 	return &TokenCountCollector{
 		grpcClient:   client,
 		initialError: initialError,
+		timeout:      timeout,
 		tokenCountDesc: prometheus.NewDesc(
 			prometheus.BuildFQName("manifest", "tokenomics", "token_count"),
 			"Total number of denominations, including native, IBC and factory tokens.",
@@ -67,8 +71,11 @@ func (c *TokenCountCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
 	bankQueryClient := bankv1beta1.NewQueryClient(c.grpcClient.Conn)
-	denomsMetaResp, denomsMetaErr := bankQueryClient.DenomsMetadata(c.grpcClient.Ctx, &bankv1beta1.QueryDenomsMetadataRequest{Pagination: &queryv1beta1.PageRequest{CountTotal: true}})
+	denomsMetaResp, denomsMetaErr := bankQueryClient.DenomsMetadata(ctx, &bankv1beta1.QueryDenomsMetadataRequest{Pagination: &queryv1beta1.PageRequest{CountTotal: true}})
 	if denomsMetaErr != nil {
 		slog.Error("Failed to query via gRPC", "query", "DenomsMetadata", "error", denomsMetaErr)
 	}
@@ -103,7 +110,7 @@ func (c *TokenCountCollector) Collect(ch chan<- prometheus.Metric) {
 }
 
 func init() {
-	RegisterCollectorFactory("token_count", func(grpcClient *client.GRPCClient, extra ...interface{}) prometheus.Collector {
-		return NewTokenCountCollector(grpcClient)
+	RegisterCollectorFactory("token_count", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		return NewTokenCountCollector(grpcClient, cfg.Timeout)
 	})
 }
@@ -0,0 +1,371 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"math/big"
+	"slices"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// trackedAccountsConcurrency is the maximum number of accounts queried concurrently.
+const trackedAccountsConcurrency = 8
+
+// TrackedAccount is an address watched by the tracked accounts collector.
+type TrackedAccount struct {
+	Label      string             `mapstructure:"label"`      // Name of the account in the metrics
+	Address    string             `mapstructure:"address"`    // Bech32 account address
+	Denoms     []string           `mapstructure:"denoms"`     // Reported denoms, all if empty
+	Thresholds []BalanceThreshold `mapstructure:"thresholds"` // Minimum balances
+}
+
+// BalanceThreshold is the minimum balance of a tracked account for a denom.
+// Thresholds are a list rather than a map keyed by denom, as configuration keys are case-insensitive and denoms are not.
+type BalanceThreshold struct {
+	Denom string  `mapstructure:"denom"` // Denom, in base units
+	Min   float64 `mapstructure:"min"`   // Minimum balance, in display units
+}
+
+// trackedAccountsOptions holds the collector-specific options of the tracked accounts collector.
+type trackedAccountsOptions struct {
+	Accounts []TrackedAccount `mapstructure:"accounts"`
+}
+
+// accountAmounts holds the amounts of a tracked account, in base units by denom.
+type accountAmounts struct {
+	balances  map[string]*big.Int
+	delegated *big.Int
+	unbonding *big.Int
+}
+
+// TrackedAccountsCollector collects the balances, delegations and unbonding delegations of configured accounts
+// from the Cosmos SDK bank and staking modules via gRPC.
+type TrackedAccountsCollector struct {
+	grpcClient         *client.GRPCClient
+	balanceDesc        *prometheus.Desc // Balance, in display units
+	delegatedDesc      *prometheus.Desc // Delegated amount, in display units
+	unbondingDesc      *prometheus.Desc // Unbonding amount, in display units
+	thresholdDesc      *prometheus.Desc // Configured minimum balance, in display units
+	belowThresholdDesc *prometheus.Desc // Whether the balance is below the minimum
+	addressValidDesc   *prometheus.Desc // Whether the configured address is valid
+	querySuccessDesc   *prometheus.Desc // Per-account query success
+	upDesc             *prometheus.Desc // gRPC query success
+	accounts           []TrackedAccount
+	invalidAccounts    []TrackedAccount
+	timeout            time.Duration
+	initialError       error
+}
+
+// NewTrackedAccountsCollector creates a new TrackedAccountsCollector.
+// It requires a gRPC client connection to query the bank and staking modules.
+// Accounts with an invalid address are reported as such and never queried.
+// Duplicate accounts, denoms or thresholds are dropped, as they would report duplicate series.
+func NewTrackedAccountsCollector(client *client.GRPCClient, accounts []TrackedAccount, timeout time.Duration) *TrackedAccountsCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	var valid, invalid []TrackedAccount
+	for _, account := range dedupeTrackedAccounts(accounts) {
+		if err := validateAddress(account.Address, bech32Prefix); err != nil {
+			slog.Error("Invalid tracked account", "label", account.Label, "error", err)
+			invalid = append(invalid, account)
+			continue
+		}
+		valid = append(valid, account)
+	}
+
+	accountLabels := []string{"label", "address", "denom", "display"}
+	return &TrackedAccountsCollector{
+		grpcClient:      client,
+		initialError:    initialError,
+		accounts:        valid,
+		invalidAccounts: invalid,
+		timeout:         timeout,
+		balanceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "balance_amount"),
+			"Balance of a tracked account, in display units.",
+			accountLabels,
+			prometheus.Labels{"source": "grpc"},
+		),
+		delegatedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "delegated_amount"),
+			"Amount delegated by a tracked account, in display units.",
+			accountLabels,
+			prometheus.Labels{"source": "grpc"},
+		),
+		unbondingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "unbonding_amount"),
+			"Amount being unbonded by a tracked account, in display units.",
+			accountLabels,
+			prometheus.Labels{"source": "grpc"},
+		),
+		thresholdDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "balance_threshold_amount"),
+			"Configured minimum balance of a tracked account, in display units.",
+			accountLabels,
+			prometheus.Labels{"source": "grpc"},
+		),
+		belowThresholdDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "below_threshold"),
+			"Whether the balance of a tracked account is below its configured minimum.",
+			accountLabels,
+			prometheus.Labels{"source": "grpc"},
+		),
+		addressValidDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "address_valid"),
+			"Whether the configured address of a tracked account is a valid bech32 address.",
+			[]string{"label", "address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		querySuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "query_success"),
+			"Whether the balances and delegations of a tracked account could be queried.",
+			[]string{"label", "address"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "account", "grpc_up"),
+			"Whether the gRPC queries were successful, i.e. the shared queries and at least one account.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "AllBalances, DelegatorDelegations, DelegatorUnbondingDelegations, Params, DenomMetadata"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *TrackedAccountsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.balanceDesc
+	ch <- c.delegatedDesc
+	ch <- c.unbondingDesc
+	ch <- c.thresholdDesc
+	ch <- c.belowThresholdDesc
+	ch <- c.addressValidDesc
+	ch <- c.querySuccessDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *TrackedAccountsCollector) Collect(ch chan<- prometheus.Metric) {
+	// Invalid addresses are reported without failing the scrape.
+	for _, account := range c.invalidAccounts {
+		collectors.ReportGaugeMetric(ch, c.addressValidDesc, 0, account.Label, account.Address)
+	}
+	for _, account := range c.accounts {
+		collectors.ReportGaugeMetric(ch, c.addressValidDesc, 1, account.Label, account.Address)
+	}
+
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.balanceDesc, err)
+		return
+	}
+	if len(c.accounts) == 0 {
+		collectors.ReportUpMetric(ch, c.upDesc, 1)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	stakingQueryClient := stakingv1beta1.NewQueryClient(c.grpcClient.Conn)
+	paramsResp, err := stakingQueryClient.Params(ctx, &stakingv1beta1.QueryParamsRequest{})
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "Params", "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.balanceDesc, err)
+		return
+	}
+	bondDenom := paramsResp.GetParams().GetBondDenom()
+
+	// Query every account. A failing account is reported by its query success and does not prevent reporting the others.
+	bankQueryClient := bankv1beta1.NewQueryClient(c.grpcClient.Conn)
+	results := make([]*accountAmounts, len(c.accounts))
+	var eg errgroup.Group
+	eg.SetLimit(trackedAccountsConcurrency)
+	for i, account := range c.accounts {
+		eg.Go(func() error {
+			amounts, err := c.queryAccount(bankQueryClient, stakingQueryClient, account.Address)
+			if err != nil {
+				slog.Error("Failed to query tracked account", "label", account.Label, "address", account.Address, "error", err)
+				return nil
+			}
+			results[i] = amounts
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	// Resolve the display unit of every reported denom.
+	denoms := map[string]struct{}{bondDenom: {}}
+	for i, account := range c.accounts {
+		for _, denom := range account.Denoms {
+			denoms[denom] = struct{}{}
+		}
+		for _, threshold := range account.Thresholds {
+			denoms[threshold.Denom] = struct{}{}
+		}
+		if results[i] == nil {
+			continue
+		}
+		for denom := range results[i].balances {
+			if isTrackedDenom(account, denom) {
+				denoms[denom] = struct{}{}
+			}
+		}
+	}
+	units, err := queryDisplayUnits(c.grpcClient.Ctx, bankQueryClient, maps.Keys(denoms), c.timeout)
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.balanceDesc, err)
+		return
+	}
+
+	failures := 0
+	for i, amounts := range results {
+		account := c.accounts[i]
+		if amounts == nil {
+			failures++
+			collectors.ReportGaugeMetric(ch, c.querySuccessDesc, 0, account.Label, account.Address)
+			continue
+		}
+		collectors.ReportGaugeMetric(ch, c.querySuccessDesc, 1, account.Label, account.Address)
+		c.collectAccount(ch, account, amounts, bondDenom, units)
+	}
+
+	upValue := 1.0
+	if failures == len(c.accounts) {
+		upValue = 0
+	}
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+// queryAccount returns the balances, delegated and unbonding amounts of an address.
+func (c *TrackedAccountsCollector) queryAccount(bankQueryClient bankv1beta1.QueryClient, stakingQueryClient stakingv1beta1.QueryClient, address string) (*accountAmounts, error) {
+	balances, err := queryAllBalances(c.grpcClient.Ctx, bankQueryClient, address, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	delegated, err := queryDelegatedAmount(c.grpcClient.Ctx, stakingQueryClient, address, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	unbonding, err := queryUnbondingAmount(c.grpcClient.Ctx, stakingQueryClient, address, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &accountAmounts{balances: balances, delegated: delegated, unbonding: unbonding}, nil
+}
+
+func (c *TrackedAccountsCollector) collectAccount(ch chan<- prometheus.Metric, account TrackedAccount, amounts *accountAmounts, bondDenom string, units map[string]displayUnitInfo) {
+	for denom, amount := range amounts.balances {
+		if !isTrackedDenom(account, denom) {
+			continue
+		}
+		unit := units[denom]
+		collectors.ReportGaugeMetric(ch, c.balanceDesc, toDisplayAmount(amount, unit.exponent), account.Label, account.Address, denom, unit.display)
+	}
+	// Selected denoms without balance are reported as zero.
+	for _, denom := range account.Denoms {
+		if _, ok := amounts.balances[denom]; !ok {
+			collectors.ReportGaugeMetric(ch, c.balanceDesc, 0, account.Label, account.Address, denom, units[denom].display)
+		}
+	}
+
+	bondUnit := units[bondDenom]
+	collectors.ReportGaugeMetric(ch, c.delegatedDesc, toDisplayAmount(amounts.delegated, bondUnit.exponent), account.Label, account.Address, bondDenom, bondUnit.display)
+	collectors.ReportGaugeMetric(ch, c.unbondingDesc, toDisplayAmount(amounts.unbonding, bondUnit.exponent), account.Label, account.Address, bondDenom, bondUnit.display)
+
+	for _, threshold := range account.Thresholds {
+		unit := units[threshold.Denom]
+		balance := new(big.Int)
+		if amount, ok := amounts.balances[threshold.Denom]; ok {
+			balance = amount
+		}
+		below := 0.0
+		if toDisplayAmount(balance, unit.exponent) < threshold.Min {
+			below = 1.0
+		}
+		collectors.ReportGaugeMetric(ch, c.thresholdDesc, threshold.Min, account.Label, account.Address, threshold.Denom, unit.display)
+		collectors.ReportGaugeMetric(ch, c.belowThresholdDesc, below, account.Label, account.Address, threshold.Denom, unit.display)
+	}
+}
+
+// dedupeTrackedAccounts drops, with an error log, the duplicate accounts by label and address, the duplicate denoms
+// and thresholds of an account, and the thresholds without denom. The first occurrence of a duplicate is kept.
+func dedupeTrackedAccounts(accounts []TrackedAccount) []TrackedAccount {
+	var result []TrackedAccount
+	seen := make(map[[2]string]struct{}, len(accounts))
+	for _, account := range accounts {
+		key := [2]string{account.Label, account.Address}
+		if _, ok := seen[key]; ok {
+			slog.Error("Skipping duplicate tracked account", "label", account.Label, "address", account.Address)
+			continue
+		}
+		seen[key] = struct{}{}
+
+		var denoms []string
+		seenDenoms := make(map[string]struct{}, len(account.Denoms))
+		for _, denom := range account.Denoms {
+			if _, ok := seenDenoms[denom]; ok {
+				slog.Error("Skipping duplicate denom of tracked account", "label", account.Label, "denom", denom)
+				continue
+			}
+			seenDenoms[denom] = struct{}{}
+			denoms = append(denoms, denom)
+		}
+
+		var thresholds []BalanceThreshold
+		seenThresholds := make(map[string]struct{}, len(account.Thresholds))
+		for _, threshold := range account.Thresholds {
+			if threshold.Denom == "" {
+				slog.Error("Skipping threshold without denom of tracked account", "label", account.Label)
+				continue
+			}
+			if _, ok := seenThresholds[threshold.Denom]; ok {
+				slog.Error("Skipping duplicate threshold of tracked account", "label", account.Label, "denom", threshold.Denom)
+				continue
+			}
+			seenThresholds[threshold.Denom] = struct{}{}
+			thresholds = append(thresholds, threshold)
+		}
+
+		account.Denoms, account.Thresholds = denoms, thresholds
+		result = append(result, account)
+	}
+	return result
+}
+
+// isTrackedDenom reports whether the balance of a denom is reported for an account.
+func isTrackedDenom(account TrackedAccount, denom string) bool {
+	return len(account.Denoms) == 0 || slices.Contains(account.Denoms, denom)
+}
+
+func init() {
+	RegisterCollectorFactory("tracked_accounts", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		var opts trackedAccountsOptions
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid tracked_accounts options", "error", err)
+		}
+		return NewTrackedAccountsCollector(grpcClient, opts.Accounts, cfg.Timeout)
+	})
+}
@@ -0,0 +1,141 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+func TestTrackedAccountsThresholdDenoms(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+collectors:
+  tracked_accounts:
+    accounts:
+      - label: Treasury
+        address: manifest1abc
+        thresholds:
+          - denom: factory/manifest1abc/uPWR
+            min: 50
+          - denom: ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2
+            min: 1.5
+`))
+	if err != nil {
+		t.Fatalf("ReadConfig() error = %v", err)
+	}
+
+	cfg, err := collectors.LoadCollectorConfig("tracked_accounts")
+	if err != nil {
+		t.Fatalf("LoadCollectorConfig() error = %v", err)
+	}
+	var opts trackedAccountsOptions
+	if err := cfg.DecodeOptions(&opts); err != nil {
+		t.Fatalf("DecodeOptions() error = %v", err)
+	}
+
+	want := []BalanceThreshold{
+		{Denom: "factory/manifest1abc/uPWR", Min: 50},
+		{Denom: "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", Min: 1.5},
+	}
+	if len(opts.Accounts) != 1 || len(opts.Accounts[0].Thresholds) != len(want) {
+		t.Fatalf("decoded accounts = %+v, want one account with %d thresholds", opts.Accounts, len(want))
+	}
+	for i, threshold := range opts.Accounts[0].Thresholds {
+		if threshold != want[i] {
+			t.Errorf("threshold %d = %+v, want %+v", i, threshold, want[i])
+		}
+	}
+	if opts.Accounts[0].Label != "Treasury" {
+		t.Errorf("label = %q, want %q", opts.Accounts[0].Label, "Treasury")
+	}
+}
+
+func TestDedupeTrackedAccounts(t *testing.T) {
+	accounts := dedupeTrackedAccounts([]TrackedAccount{
+		{Label: "faucet", Address: "manifest1a", Denoms: []string{"umfx", "umfx", "upwr"}, Thresholds: []BalanceThreshold{
+			{Denom: "umfx", Min: 1}, {Denom: "umfx", Min: 2}, {Min: 3},
+		}},
+		{Label: "relayer", Address: "manifest1a"},
+		{Label: "faucet", Address: "manifest1a", Denoms: []string{"uother"}},
+	})
+
+	if len(accounts) != 2 {
+		t.Fatalf("accounts = %+v, want the faucet and relayer accounts", accounts)
+	}
+	faucet, relayer := accounts[0], accounts[1]
+	if !slices.Equal(faucet.Denoms, []string{"umfx", "upwr"}) {
+		t.Errorf("faucet denoms = %v, want [umfx upwr]", faucet.Denoms)
+	}
+	if !slices.Equal(faucet.Thresholds, []BalanceThreshold{{Denom: "umfx", Min: 1}}) {
+		t.Errorf("faucet thresholds = %+v, want the first umfx threshold", faucet.Thresholds)
+	}
+	if relayer.Label != "relayer" || len(relayer.Denoms) != 0 {
+		t.Errorf("relayer = %+v, want the relayer account reporting every denom", relayer)
+	}
+}
+
+// fakeAccountsBankServer serves the balances of the tracked accounts, failing the addresses in failing.
+type fakeAccountsBankServer struct {
+	fakeMetadataServer
+	failing map[string]bool
+}
+
+func (s fakeAccountsBankServer) AllBalances(_ context.Context, req *bankv1beta1.QueryAllBalancesRequest) (*bankv1beta1.QueryAllBalancesResponse, error) {
+	if s.failing[req.Address] {
+		return nil, status.Error(codes.Unavailable, "node unreachable")
+	}
+	return &bankv1beta1.QueryAllBalancesResponse{Balances: []*basev1beta1.Coin{{Denom: "umfx", Amount: "2000000"}}}, nil
+}
+
+// fakeAccountsStakingServer serves accounts without delegations.
+type fakeAccountsStakingServer struct {
+	fakeStakingServer
+}
+
+func (fakeAccountsStakingServer) DelegatorDelegations(context.Context, *stakingv1beta1.QueryDelegatorDelegationsRequest) (*stakingv1beta1.QueryDelegatorDelegationsResponse, error) {
+	return &stakingv1beta1.QueryDelegatorDelegationsResponse{}, nil
+}
+
+func (fakeAccountsStakingServer) DelegatorUnbondingDelegations(context.Context, *stakingv1beta1.QueryDelegatorUnbondingDelegationsRequest) (*stakingv1beta1.QueryDelegatorUnbondingDelegationsResponse, error) {
+	return &stakingv1beta1.QueryDelegatorUnbondingDelegationsResponse{}, nil
+}
+
+func TestTrackedAccountsCollectorPartialFailure(t *testing.T) {
+	ok, failing := testAddress(t, bech32Prefix, 1), testAddress(t, bech32Prefix, 2)
+
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		bankv1beta1.RegisterQueryServer(server, fakeAccountsBankServer{failing: map[string]bool{failing: true}})
+		stakingv1beta1.RegisterQueryServer(server, fakeAccountsStakingServer{})
+	})
+	got := gatherValues(t, NewTrackedAccountsCollector(grpcClient, []TrackedAccount{
+		{Label: "faucet", Address: ok},
+		{Label: "relayer", Address: failing},
+		{Label: "typo", Address: "manifest1invalid"},
+	}, time.Second))
+
+	want := map[string]float64{
+		"manifest_account_balance_amount{address=" + ok + ",denom=umfx,display=mfx,label=faucet}": 2,
+		"manifest_account_query_success{address=" + ok + ",label=faucet}":                         1,
+		"manifest_account_query_success{address=" + failing + ",label=relayer}":                   0,
+		"manifest_account_address_valid{address=manifest1invalid,label=typo}":                     0,
+		"manifest_account_grpc_up": 1,
+	}
+	assertValues(t, got, want)
+}
@@ -0,0 +1,235 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	upgradev1beta1 "cosmossdk.io/api/cosmos/upgrade/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+	"github.com/manifest-network/manifest-node-exporter/pkg/utils"
+)
+
+// defaultBlockTimeWindow is the default number of recent blocks used to estimate the block time.
+const defaultBlockTimeWindow = 100
+
+// upgradeOptions holds the collector-specific options of the upgrade collector.
+type upgradeOptions struct {
+	BlockTimeWindow int64 `mapstructure:"block_time_window"` // Number of recent blocks used to estimate the block time
+}
+
+// UpgradeCollector collects the pending software upgrade plan from the Cosmos SDK upgrade module via gRPC.
+type UpgradeCollector struct {
+	grpcClient          *client.GRPCClient
+	pendingDesc         *prometheus.Desc // Whether an upgrade is planned
+	planHeightDesc      *prometheus.Desc // Upgrade height
+	blocksRemainingDesc *prometheus.Desc // Blocks until the upgrade
+	timeRemainingDesc   *prometheus.Desc // Estimated time until the upgrade
+	versionMatchDesc    *prometheus.Desc // Whether the node runs the upgraded version
+	upDesc              *prometheus.Desc // gRPC query success
+	blockTimeWindow     int64
+	timeout             time.Duration
+	initialError        error
+}
+
+// NewUpgradeCollector creates a new UpgradeCollector.
+// It requires a gRPC client connection to query the upgrade module and the tendermint service.
+// The time until the upgrade is estimated from the average time of the last blockTimeWindow blocks.
+// Whether the node runs the upgraded version is reported for the pending plan, and for the applied plan
+// named after the running version, as the upgraded version clears the plan once it is applied.
+func NewUpgradeCollector(client *client.GRPCClient, blockTimeWindow int64, timeout time.Duration) *UpgradeCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	return &UpgradeCollector{
+		grpcClient:      client,
+		initialError:    initialError,
+		blockTimeWindow: blockTimeWindow,
+		timeout:         timeout,
+		pendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "upgrade", "pending"),
+			"Whether a software upgrade is planned.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		planHeightDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "upgrade", "plan_height"),
+			"Height at which the planned upgrade is applied.",
+			[]string{"name"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		blocksRemainingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "upgrade", "blocks_remaining"),
+			"Number of blocks to commit before the node halts for the planned upgrade.",
+			[]string{"name"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		timeRemainingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "upgrade", "estimated_seconds_remaining"),
+			"Estimated seconds until the planned upgrade, from the average time of recent blocks.",
+			[]string{"name"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		versionMatchDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "upgrade", "version_match"),
+			"Whether the application version of the node matches the name of the pending or applied upgrade.",
+			[]string{"name", "app_version"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "upgrade", "grpc_up"),
+			"Whether the gRPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "CurrentPlan, AppliedPlan, GetLatestBlock, GetBlockByHeight, GetNodeInfo"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *UpgradeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.pendingDesc
+	ch <- c.planHeightDesc
+	ch <- c.blocksRemainingDesc
+	ch <- c.timeRemainingDesc
+	ch <- c.versionMatchDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *UpgradeCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.pendingDesc, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	upgradeQueryClient := upgradev1beta1.NewQueryClient(c.grpcClient.Conn)
+	planResp, err := upgradeQueryClient.CurrentPlan(ctx, &upgradev1beta1.QueryCurrentPlanRequest{})
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "CurrentPlan", "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.pendingDesc, err)
+		return
+	}
+
+	nodeInfoResp, err := utils.QueryNodeInfo(ctx, c.grpcClient.Conn)
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetNodeInfo", "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.versionMatchDesc, err)
+		return
+	}
+	appVersion := nodeInfoResp.GetApplicationVersion().GetVersion()
+
+	plan := planResp.GetPlan()
+	if plan == nil {
+		collectors.ReportGaugeMetric(ch, c.pendingDesc, 0)
+		upValue := 1.0
+		if err := c.collectApplied(ctx, ch, upgradeQueryClient, appVersion); err != nil {
+			slog.Error("Failed to query via gRPC", "query", "AppliedPlan", "error", err)
+			collectors.ReportInvalidMetric(ch, c.versionMatchDesc, err)
+			upValue = 0
+		}
+		collectors.ReportUpMetric(ch, c.upDesc, upValue)
+		return
+	}
+	collectors.ReportGaugeMetric(ch, c.pendingDesc, 1)
+	collectors.ReportGaugeMetric(ch, c.planHeightDesc, float64(plan.Height), plan.Name)
+
+	// The node runs the previous version until it halts for the upgrade, so a mismatch is expected until then.
+	match := 0.0
+	if versionMatchesPlan(appVersion, plan.Name) {
+		match = 1.0
+	}
+	collectors.ReportGaugeMetric(ch, c.versionMatchDesc, match, plan.Name, appVersion)
+
+	upValue := 1.0
+	tmQueryClient := tmv1beta1.NewServiceClient(c.grpcClient.Conn)
+	latest, err := queryLatestBlockHeader(ctx, tmQueryClient)
+	if err != nil {
+		slog.Error("Failed to query via gRPC", "query", "GetLatestBlock", "error", err)
+		collectors.ReportInvalidMetric(ch, c.blocksRemainingDesc, err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		return
+	}
+	// The last block committed before the upgrade is the one below the upgrade height.
+	remaining := max(plan.Height-1-latest.Height, 0)
+	if err := c.collectRemaining(ctx, ch, tmQueryClient, plan.Name, remaining, latest); err != nil {
+		slog.Error("Failed to estimate the time until the upgrade", "name", plan.Name, "error", err)
+		collectors.ReportInvalidMetric(ch, c.timeRemainingDesc, err)
+		upValue = 0
+	}
+
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+}
+
+// collectRemaining reports the number of remaining blocks and the estimated time until the upgrade.
+func (c *UpgradeCollector) collectRemaining(ctx context.Context, ch chan<- prometheus.Metric, tmQueryClient tmv1beta1.ServiceClient, name string, remaining int64, latest *blockHeader) error {
+	collectors.ReportGaugeMetric(ch, c.blocksRemainingDesc, float64(remaining), name)
+
+	window := min(c.blockTimeWindow, latest.Height-1)
+	if window <= 0 {
+		return nil
+	}
+	past, err := queryBlockHeader(ctx, tmQueryClient, latest.Height-window)
+	if err != nil {
+		return err
+	}
+	blockTime := latest.Time.Sub(past.Time).Seconds() / float64(window)
+	collectors.ReportGaugeMetric(ch, c.timeRemainingDesc, blockTime*float64(remaining), name)
+	return nil
+}
+
+// collectApplied reports that the node runs an upgraded version when an upgrade plan named after its
+// application version was applied, with or without the "v" prefix. Nothing is reported otherwise.
+func (c *UpgradeCollector) collectApplied(ctx context.Context, ch chan<- prometheus.Metric, upgradeQueryClient upgradev1beta1.QueryClient, appVersion string) error {
+	if appVersion == "" {
+		return nil
+	}
+	version := strings.TrimPrefix(appVersion, "v")
+	for _, name := range []string{"v" + version, version} {
+		resp, err := upgradeQueryClient.AppliedPlan(ctx, &upgradev1beta1.QueryAppliedPlanRequest{Name: name})
+		if err != nil {
+			return err
+		}
+		if resp.GetHeight() > 0 {
+			collectors.ReportGaugeMetric(ch, c.versionMatchDesc, 1, name, appVersion)
+			return nil
+		}
+	}
+	return nil
+}
+
+// versionMatchesPlan reports whether an application version matches an upgrade name.
+// Upgrades are usually named after the version they introduce, with or without the "v" prefix.
+func versionMatchesPlan(appVersion, planName string) bool {
+	return appVersion != "" && strings.TrimPrefix(appVersion, "v") == strings.TrimPrefix(planName, "v")
+}
+
+func init() {
+	RegisterCollectorFactory("upgrade", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		opts := upgradeOptions{BlockTimeWindow: defaultBlockTimeWindow}
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid upgrade options", "error", err)
+		}
+		return NewUpgradeCollector(grpcClient, opts.BlockTimeWindow, cfg.Timeout)
+	})
+}
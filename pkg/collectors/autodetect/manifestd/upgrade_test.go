@@ -0,0 +1,122 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	upgradev1beta1 "cosmossdk.io/api/cosmos/upgrade/v1beta1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeUpgradeNode serves the upgrade and tendermint queries used by the upgrade collector.
+type fakeUpgradeNode struct {
+	upgradev1beta1.UnimplementedQueryServer
+	tmv1beta1.UnimplementedServiceServer
+	plan       *upgradev1beta1.Plan
+	applied    map[string]int64
+	height     int64
+	appVersion string
+}
+
+func (n *fakeUpgradeNode) CurrentPlan(context.Context, *upgradev1beta1.QueryCurrentPlanRequest) (*upgradev1beta1.QueryCurrentPlanResponse, error) {
+	return &upgradev1beta1.QueryCurrentPlanResponse{Plan: n.plan}, nil
+}
+
+func (n *fakeUpgradeNode) AppliedPlan(_ context.Context, req *upgradev1beta1.QueryAppliedPlanRequest) (*upgradev1beta1.QueryAppliedPlanResponse, error) {
+	return &upgradev1beta1.QueryAppliedPlanResponse{Height: n.applied[req.Name]}, nil
+}
+
+func (n *fakeUpgradeNode) GetNodeInfo(context.Context, *tmv1beta1.GetNodeInfoRequest) (*tmv1beta1.GetNodeInfoResponse, error) {
+	return &tmv1beta1.GetNodeInfoResponse{ApplicationVersion: &tmv1beta1.VersionInfo{Version: n.appVersion}}, nil
+}
+
+func (n *fakeUpgradeNode) block(height int64) *tmv1beta1.Block {
+	return &tmv1beta1.Block{Header: &tmv1beta1.Header{Height: height, Time: timestamppb.New(time.Unix(height*5, 0))}}
+}
+
+func (n *fakeUpgradeNode) GetLatestBlock(context.Context, *tmv1beta1.GetLatestBlockRequest) (*tmv1beta1.GetLatestBlockResponse, error) {
+	return &tmv1beta1.GetLatestBlockResponse{SdkBlock: n.block(n.height)}, nil
+}
+
+func (n *fakeUpgradeNode) GetBlockByHeight(_ context.Context, req *tmv1beta1.GetBlockByHeightRequest) (*tmv1beta1.GetBlockByHeightResponse, error) {
+	return &tmv1beta1.GetBlockByHeightResponse{SdkBlock: n.block(req.Height)}, nil
+}
+
+func newUpgradeCollector(t *testing.T, node *fakeUpgradeNode) *UpgradeCollector {
+	t.Helper()
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		upgradev1beta1.RegisterQueryServer(server, node)
+		tmv1beta1.RegisterServiceServer(server, node)
+	})
+	return NewUpgradeCollector(grpcClient, 10, time.Second)
+}
+
+func TestUpgradeCollectorVersionMatch(t *testing.T) {
+	plan := &upgradev1beta1.Plan{Name: "v2.0.0", Height: 1000}
+	tests := []struct {
+		name      string
+		node      *fakeUpgradeNode
+		remaining float64            // Expected blocks_remaining, not checked without a pending plan
+		want      map[string]float64 // Expected version_match series, none if empty
+	}{
+		{
+			name:      "previous binary before the upgrade height",
+			node:      &fakeUpgradeNode{plan: plan, height: 900, appVersion: "v1.0.0"},
+			remaining: 99,
+			want:      map[string]float64{"manifest_upgrade_version_match{app_version=v1.0.0,name=v2.0.0}": 0},
+		},
+		{
+			name:      "previous binary halted below the upgrade height",
+			node:      &fakeUpgradeNode{plan: plan, height: 999, appVersion: "v1.0.0"},
+			remaining: 0,
+			want:      map[string]float64{"manifest_upgrade_version_match{app_version=v1.0.0,name=v2.0.0}": 0},
+		},
+		{
+			name:      "upgraded binary started below the upgrade height",
+			node:      &fakeUpgradeNode{plan: plan, height: 999, appVersion: "2.0.0"},
+			remaining: 0,
+			want:      map[string]float64{"manifest_upgrade_version_match{app_version=2.0.0,name=v2.0.0}": 1},
+		},
+		{
+			name: "applied upgrade",
+			node: &fakeUpgradeNode{height: 1200, appVersion: "2.0.0", applied: map[string]int64{"v2.0.0": 1000}},
+			want: map[string]float64{"manifest_upgrade_version_match{app_version=2.0.0,name=v2.0.0}": 1},
+		},
+		{
+			name: "no upgrade applied",
+			node: &fakeUpgradeNode{height: 1200, appVersion: "v1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gatherValues(t, newUpgradeCollector(t, tt.node))
+			if got["manifest_upgrade_grpc_up"] != 1 {
+				t.Errorf("grpc_up = %v, want 1", got["manifest_upgrade_grpc_up"])
+			}
+			if tt.node.plan != nil {
+				assertValues(t, got, map[string]float64{"manifest_upgrade_blocks_remaining{name=v2.0.0}": tt.remaining})
+			}
+			var matches int
+			for key, value := range got {
+				if !strings.HasPrefix(key, "manifest_upgrade_version_match") {
+					continue
+				}
+				matches++
+				if want, ok := tt.want[key]; !ok || want != value {
+					t.Errorf("%s = %v, want %v (present: %v)", key, value, want, ok)
+				}
+			}
+			if matches != len(tt.want) {
+				t.Errorf("version_match series = %d, want %d", matches, len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/client"
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// validatorsOptions holds the collector-specific options of the validators collector.
+type validatorsOptions struct {
+	PerValidator bool `mapstructure:"per_validator"` // Report per-validator series
+}
+
+// ValidatorsCollector collects the validator set and staking pool metrics from the Cosmos SDK staking module via gRPC.
+type ValidatorsCollector struct {
+	grpcClient          *client.GRPCClient
+	validatorsDesc      *prometheus.Desc // Number of validators by status
+	jailedDesc          *prometheus.Desc // Number of jailed validators
+	poolBondedDesc      *prometheus.Desc // Bonded tokens, in display units
+	poolNotBondedDesc   *prometheus.Desc // Not bonded tokens, in display units
+	validatorTokensDesc *prometheus.Desc // Per-validator tokens, in display units
+	validatorRateDesc   *prometheus.Desc // Per-validator commission rate
+	validatorStatusDesc *prometheus.Desc // Per-validator bond status
+	validatorJailedDesc *prometheus.Desc // Per-validator jailed flag
+	upDesc              *prometheus.Desc // gRPC query success
+	perValidator        bool
+	timeout             time.Duration
+	initialError        error
+}
+
+// NewValidatorsCollector creates a new ValidatorsCollector.
+// It requires a gRPC client connection to query the staking module.
+// Per-validator series are only reported if perValidator is set.
+func NewValidatorsCollector(client *client.GRPCClient, perValidator bool, timeout time.Duration) *ValidatorsCollector {
+	var initialError error
+	if client == nil {
+		initialError = status.Error(codes.Internal, "gRPC client is nil")
+	} else if client.Conn == nil {
+		initialError = status.Error(codes.Internal, "gRPC client connection is nil")
+	}
+
+	return &ValidatorsCollector{
+		grpcClient:   client,
+		initialError: initialError,
+		perValidator: perValidator,
+		timeout:      timeout,
+		validatorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validators"),
+			"Number of validators, by bond status.",
+			[]string{"status"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		jailedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validators_jailed"),
+			"Number of jailed validators.",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+		poolBondedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "pool_bonded_tokens"),
+			"Tokens bonded to validators, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		poolNotBondedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "pool_not_bonded_tokens"),
+			"Tokens not bonded to any validator, in display units.",
+			[]string{"denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		validatorTokensDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validator_tokens"),
+			"Tokens delegated to a validator, in display units.",
+			[]string{"validator", "moniker", "denom", "display"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		validatorRateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validator_commission_rate"),
+			"Commission rate of a validator.",
+			[]string{"validator", "moniker"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		validatorStatusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validator_status"),
+			"Bond status of a validator (1: unbonded, 2: unbonding, 3: bonded).",
+			[]string{"validator", "moniker"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		validatorJailedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validator_jailed"),
+			"Whether a validator is jailed.",
+			[]string{"validator", "moniker"},
+			prometheus.Labels{"source": "grpc"},
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "staking", "validators_grpc_up"),
+			"Whether the gRPC queries were successful.",
+			nil,
+			prometheus.Labels{"source": "grpc", "queries": "Validators, Pool, Params, DenomMetadata"},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *ValidatorsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.validatorsDesc
+	ch <- c.jailedDesc
+	ch <- c.poolBondedDesc
+	ch <- c.poolNotBondedDesc
+	ch <- c.validatorTokensDesc
+	ch <- c.validatorRateDesc
+	ch <- c.validatorStatusDesc
+	ch <- c.validatorJailedDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ValidatorsCollector) Collect(ch chan<- prometheus.Metric) {
+	// Check for initialization or connection errors first.
+	if err := collectors.ValidateClient(c.grpcClient, c.initialError); err != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0) // Report gRPC down
+		collectors.ReportInvalidMetric(ch, c.validatorsDesc, err)
+		collectors.ReportInvalidMetric(ch, c.poolBondedDesc, err)
+		return
+	}
+
+	stakingQueryClient := stakingv1beta1.NewQueryClient(c.grpcClient.Conn)
+	validators, validatorsErr := queryAllValidators(c.grpcClient.Ctx, stakingQueryClient, c.timeout)
+	if validatorsErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Validators", "error", validatorsErr)
+	}
+
+	ctx, cancel := context.WithTimeout(c.grpcClient.Ctx, c.timeout)
+	defer cancel()
+
+	poolResp, poolErr := stakingQueryClient.Pool(ctx, &stakingv1beta1.QueryPoolRequest{})
+	if poolErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Pool", "error", poolErr)
+	}
+
+	// Token amounts are scaled by the display unit of the bond denom.
+	var unit bondDenomUnit
+	paramsResp, unitErr := stakingQueryClient.Params(ctx, &stakingv1beta1.QueryParamsRequest{})
+	if unitErr != nil {
+		slog.Error("Failed to query via gRPC", "query", "Params", "error", unitErr)
+	} else {
+		unit.denom = paramsResp.GetParams().GetBondDenom()
+		unit.display, unit.exponent, unitErr = queryDisplayUnit(ctx, bankv1beta1.NewQueryClient(c.grpcClient.Conn), unit.denom)
+		if unitErr != nil {
+			slog.Error("Failed to query via gRPC", "query", "DenomMetadata", "denom", unit.denom, "error", unitErr)
+		}
+	}
+
+	// Report 'up' metric based on query success
+	upValue := 0.0
+	if validatorsErr == nil && poolErr == nil && unitErr == nil {
+		upValue = 1.0
+	}
+	collectors.ReportUpMetric(ch, c.upDesc, upValue)
+
+	if validatorsErr != nil {
+		collectors.ReportInvalidMetric(ch, c.validatorsDesc, validatorsErr)
+	} else {
+		c.collectValidators(ch, validators, unit, unitErr)
+	}
+
+	switch {
+	case poolErr != nil:
+		collectors.ReportInvalidMetric(ch, c.poolBondedDesc, poolErr)
+	case unitErr != nil:
+		collectors.ReportInvalidMetric(ch, c.poolBondedDesc, unitErr)
+	default:
+		c.collectPool(ch, poolResp.GetPool(), unit)
+	}
+}
+
+// bondDenomUnit holds the bond denom and its display unit.
+type bondDenomUnit struct {
+	denom    string
+	display  string
+	exponent uint32
+}
+
+// collectValidators reports the validator counts, and the per-validator series if enabled.
+// The per-validator tokens are reported invalid with unitErr when the display unit of the bond denom is unknown.
+func (c *ValidatorsCollector) collectValidators(ch chan<- prometheus.Metric, validators []*stakingv1beta1.Validator, unit bondDenomUnit, unitErr error) {
+	counts := map[string]int{"bonded": 0, "unbonding": 0, "unbonded": 0}
+	jailed := 0
+	for _, val := range validators {
+		counts[bondStatusLabel(val.Status)]++
+		if val.Jailed {
+			jailed++
+		}
+		if c.perValidator {
+			c.collectValidator(ch, val, unit, unitErr)
+		}
+	}
+
+	for bondStatus, count := range counts {
+		collectors.ReportGaugeMetric(ch, c.validatorsDesc, float64(count), bondStatus)
+	}
+	collectors.ReportGaugeMetric(ch, c.jailedDesc, float64(jailed))
+	if c.perValidator && unitErr != nil {
+		collectors.ReportInvalidMetric(ch, c.validatorTokensDesc, unitErr)
+	}
+}
+
+func (c *ValidatorsCollector) collectValidator(ch chan<- prometheus.Metric, val *stakingv1beta1.Validator, unit bondDenomUnit, unitErr error) {
+	moniker := val.GetDescription().GetMoniker()
+
+	tokens, err := parseAmount(val.Tokens)
+	if err != nil {
+		slog.Error("Failed to parse validator tokens", "validator", val.OperatorAddress, "error", err)
+		collectors.ReportInvalidMetric(ch, c.validatorTokensDesc, err)
+	} else if unitErr == nil {
+		collectors.ReportGaugeMetric(ch, c.validatorTokensDesc, toDisplayAmount(tokens, unit.exponent), val.OperatorAddress, moniker, unit.denom, unit.display)
+	}
+
+	rate, err := parseLegacyDec(val.GetCommission().GetCommissionRates().GetRate())
+	if err != nil {
+		slog.Error("Failed to parse validator commission rate", "validator", val.OperatorAddress, "error", err)
+		collectors.ReportInvalidMetric(ch, c.validatorRateDesc, err)
+	} else {
+		rateValue, _ := rate.Float64()
+		collectors.ReportGaugeMetric(ch, c.validatorRateDesc, rateValue, val.OperatorAddress, moniker)
+	}
+
+	jailed := 0.0
+	if val.Jailed {
+		jailed = 1.0
+	}
+	collectors.ReportGaugeMetric(ch, c.validatorStatusDesc, float64(val.Status), val.OperatorAddress, moniker)
+	collectors.ReportGaugeMetric(ch, c.validatorJailedDesc, jailed, val.OperatorAddress, moniker)
+}
+
+func (c *ValidatorsCollector) collectPool(ch chan<- prometheus.Metric, pool *stakingv1beta1.Pool, unit bondDenomUnit) {
+	if pool == nil {
+		collectors.ReportInvalidMetric(ch, c.poolBondedDesc, status.Error(codes.Internal, "Pool response is nil"))
+		return
+	}
+
+	bonded, err := parseAmount(pool.BondedTokens)
+	if err != nil {
+		collectors.ReportInvalidMetric(ch, c.poolBondedDesc, err)
+	} else {
+		collectors.ReportGaugeMetric(ch, c.poolBondedDesc, toDisplayAmount(bonded, unit.exponent), unit.denom, unit.display)
+	}
+
+	notBonded, err := parseAmount(pool.NotBondedTokens)
+	if err != nil {
+		collectors.ReportInvalidMetric(ch, c.poolNotBondedDesc, err)
+	} else {
+		collectors.ReportGaugeMetric(ch, c.poolNotBondedDesc, toDisplayAmount(notBonded, unit.exponent), unit.denom, unit.display)
+	}
+}
+
+func init() {
+	RegisterCollectorFactory("validators", func(grpcClient *client.GRPCClient, cfg collectors.CollectorConfig) prometheus.Collector {
+		var opts validatorsOptions
+		if err := cfg.DecodeOptions(&opts); err != nil {
+			slog.Error("Invalid validators options", "error", err)
+		}
+		return NewValidatorsCollector(grpcClient, opts.PerValidator, cfg.Timeout)
+	})
+}
@@ -0,0 +1,29 @@
+//go:build manifest_node_exporter
+// +build manifest_node_exporter
+
+package manifestd
+
+import (
+	"testing"
+	"time"
+
+	bankv1beta1 "cosmossdk.io/api/cosmos/bank/v1beta1"
+	stakingv1beta1 "cosmossdk.io/api/cosmos/staking/v1beta1"
+	"google.golang.org/grpc"
+)
+
+func TestValidatorsCollectorDisplayUnits(t *testing.T) {
+	grpcClient := newFakeGRPCClient(t, func(server *grpc.Server) {
+		stakingv1beta1.RegisterQueryServer(server, fakeStakingServer{})
+		bankv1beta1.RegisterQueryServer(server, fakeMetadataServer{})
+	})
+	got := gatherValues(t, NewValidatorsCollector(grpcClient, true, time.Second))
+
+	want := map[string]float64{
+		"manifest_staking_pool_bonded_tokens{denom=umfx,display=mfx}":                                           2.5,
+		"manifest_staking_pool_not_bonded_tokens{denom=umfx,display=mfx}":                                       0.5,
+		"manifest_staking_validator_tokens{denom=umfx,display=mfx,moniker=alice,validator=manifestvaloper1abc}": 2.5,
+		"manifest_staking_validators_grpc_up":                                                                   1,
+	}
+	assertValues(t, got, want)
+}
@@ -0,0 +1,212 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	vestingv1beta1 "cosmossdk.io/api/cosmos/vesting/v1beta1"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// defaultVestingRefresh is the default minimum time between two enumerations of the vesting accounts.
+const defaultVestingRefresh = 10 * time.Minute
+
+// Types of vesting accounts, as reported by the vesting metrics.
+const (
+	continuousVesting = "continuous"
+	delayedVesting    = "delayed"
+	periodicVesting   = "periodic"
+	permanentLocked   = "permanent_locked"
+)
+
+// vestingAccount holds the vesting schedule of an account for a single denom.
+type vestingAccount struct {
+	Kind     string // continuous, delayed, periodic or permanent_locked
+	Address  string
+	Original *big.Int // Original vesting amount, in base units
+	Start    int64    // Unix time at which vesting starts, for continuous and periodic accounts
+	End      int64    // Unix time at which vesting ends
+	Periods  []vestingPeriod
+}
+
+// vestingPeriod is a period of a periodic vesting account.
+type vestingPeriod struct {
+	Length int64    // Duration of the period, in seconds
+	Amount *big.Int // Amount vested at the end of the period, in base units
+}
+
+// locked returns the amount that is still vesting at the given block time, in base units.
+// It follows the vesting rules of the Cosmos SDK x/auth/vesting module, and is the vesting amount of the SDK
+// (GetVestingCoins) rather than its locked amount (LockedCoins): the SDK subtracts the delegated vesting tokens, which
+// are held by the bonded pool instead of the account, while they are still unvested and not circulating.
+func (a *vestingAccount) locked(blockTime time.Time) *big.Int {
+	now := blockTime.Unix()
+	vested := new(big.Int)
+	switch a.Kind {
+	case continuousVesting:
+		if now >= a.End {
+			vested.Set(a.Original)
+		} else if now > a.Start {
+			vested.Mul(a.Original, big.NewInt(now-a.Start))
+			vested.Quo(vested, big.NewInt(a.End-a.Start))
+		}
+	case delayedVesting:
+		if now >= a.End {
+			vested.Set(a.Original)
+		}
+	case periodicVesting:
+		if now >= a.End {
+			vested.Set(a.Original)
+		} else if now > a.Start {
+			periodStart := a.Start
+			for _, period := range a.Periods {
+				if now-periodStart < period.Length {
+					break
+				}
+				vested.Add(vested, period.Amount)
+				periodStart += period.Length
+			}
+		}
+	}
+
+	locked := new(big.Int).Sub(a.Original, vested)
+	if locked.Sign() < 0 {
+		return new(big.Int)
+	}
+	return locked
+}
+
+// queryVestingAccounts returns the vesting schedule of every vesting account holding the denom.
+// All pages of accounts are fetched, each page request being bounded by the given timeout.
+func queryVestingAccounts(ctx context.Context, authQueryClient authv1beta1.QueryClient, denom string, timeout time.Duration) ([]*vestingAccount, error) {
+	var accounts []*vestingAccount
+	err := collectors.FetchAllPages(func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error) {
+		pageCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := authQueryClient.Accounts(pageCtx, &authv1beta1.QueryAccountsRequest{Pagination: page})
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range resp.Accounts {
+			vesting, err := decodeVestingAccount(account, denom)
+			if err != nil {
+				return nil, err
+			}
+			if vesting != nil && vesting.Original.Sign() > 0 {
+				accounts = append(accounts, vesting)
+			}
+		}
+		return resp.Pagination, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// vestingAccountCache holds the vesting accounts of the chain between two enumerations.
+// Enumerating them pages through every account of the chain, while the vesting schedules rarely change:
+// the locked amounts are computed from the cached schedules at the time of each block.
+type vestingAccountCache struct {
+	refresh time.Duration
+
+	mu        sync.Mutex
+	accounts  []*vestingAccount
+	fetchedAt time.Time
+}
+
+// newVestingAccountCache creates a cache enumerating the vesting accounts at most once per refresh period.
+func newVestingAccountCache(refresh time.Duration) *vestingAccountCache {
+	if refresh <= 0 {
+		refresh = defaultVestingRefresh
+	}
+	return &vestingAccountCache{refresh: refresh}
+}
+
+// Accounts returns the cached vesting accounts holding the denom, enumerating them again once the refresh period is over.
+// Concurrent callers wait for a single enumeration.
+func (c *vestingAccountCache) Accounts(ctx context.Context, authQueryClient authv1beta1.QueryClient, denom string, timeout time.Duration) ([]*vestingAccount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.refresh {
+		return c.accounts, nil
+	}
+	accounts, err := queryVestingAccounts(ctx, authQueryClient, denom, timeout)
+	if err != nil {
+		return nil, err
+	}
+	c.accounts, c.fetchedAt = accounts, time.Now()
+	return accounts, nil
+}
+
+// decodeVestingAccount decodes the vesting schedule of an account for the denom.
+// It returns nil if the account is not a vesting account.
+func decodeVestingAccount(account *anypb.Any, denom string) (*vestingAccount, error) {
+	var (
+		vesting = &vestingAccount{}
+		base    *vestingv1beta1.BaseVestingAccount
+		err     error
+	)
+	switch {
+	case account.MessageIs(&vestingv1beta1.ContinuousVestingAccount{}):
+		var continuous vestingv1beta1.ContinuousVestingAccount
+		err = account.UnmarshalTo(&continuous)
+		vesting.Kind, vesting.Start, base = continuousVesting, continuous.StartTime, continuous.GetBaseVestingAccount()
+	case account.MessageIs(&vestingv1beta1.DelayedVestingAccount{}):
+		var delayed vestingv1beta1.DelayedVestingAccount
+		err = account.UnmarshalTo(&delayed)
+		vesting.Kind, base = delayedVesting, delayed.GetBaseVestingAccount()
+	case account.MessageIs(&vestingv1beta1.PeriodicVestingAccount{}):
+		var periodic vestingv1beta1.PeriodicVestingAccount
+		if err = account.UnmarshalTo(&periodic); err != nil {
+			break
+		}
+		vesting.Kind, vesting.Start, base = periodicVesting, periodic.StartTime, periodic.GetBaseVestingAccount()
+		for _, period := range periodic.VestingPeriods {
+			amount, err := coinAmountOf(period.Amount, denom)
+			if err != nil {
+				return nil, err
+			}
+			vesting.Periods = append(vesting.Periods, vestingPeriod{Length: period.Length, Amount: amount})
+		}
+	case account.MessageIs(&vestingv1beta1.PermanentLockedAccount{}):
+		var permanent vestingv1beta1.PermanentLockedAccount
+		err = account.UnmarshalTo(&permanent)
+		vesting.Kind, base = permanentLocked, permanent.GetBaseVestingAccount()
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode vesting account: %w", err)
+	}
+
+	vesting.Address = base.GetBaseAccount().GetAddress()
+	vesting.End = base.GetEndTime()
+	if vesting.Original, err = coinAmountOf(base.GetOriginalVesting(), denom); err != nil {
+		return nil, err
+	}
+	return vesting, nil
+}
+
+// coinAmountOf returns the amount of the denom in a list of coins, in base units.
+func coinAmountOf(coins []*basev1beta1.Coin, denom string) (*big.Int, error) {
+	for _, coin := range coins {
+		if coin.GetDenom() == denom {
+			return parseAmount(coin.GetAmount())
+		}
+	}
+	return new(big.Int), nil
+}
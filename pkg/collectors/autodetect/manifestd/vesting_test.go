@@ -0,0 +1,95 @@
+//go:build manifest_excluded_supply_exporter
+// +build manifest_excluded_supply_exporter
+
+package manifestd
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	authv1beta1 "cosmossdk.io/api/cosmos/auth/v1beta1"
+	basev1beta1 "cosmossdk.io/api/cosmos/base/v1beta1"
+	vestingv1beta1 "cosmossdk.io/api/cosmos/vesting/v1beta1"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestVestingAccountLocked(t *testing.T) {
+	const start, end = int64(1_700_000_000), int64(1_700_000_400)
+	continuous := &vestingAccount{Kind: continuousVesting, Original: big.NewInt(1000), Start: start, End: end}
+	delayed := &vestingAccount{Kind: delayedVesting, Original: big.NewInt(1000), End: end}
+	// Four periods of 100 seconds, vesting 100, 200, 300 and 400.
+	periodic := &vestingAccount{Kind: periodicVesting, Original: big.NewInt(1000), Start: start, End: end, Periods: []vestingPeriod{
+		{Length: 100, Amount: big.NewInt(100)},
+		{Length: 100, Amount: big.NewInt(200)},
+		{Length: 100, Amount: big.NewInt(300)},
+		{Length: 100, Amount: big.NewInt(400)},
+	}}
+	permanent := &vestingAccount{Kind: permanentLocked, Original: big.NewInt(1000), End: end}
+
+	tests := []struct {
+		name    string
+		account *vestingAccount
+		at      int64
+		want    int64
+	}{
+		{"continuous before start", continuous, start - 1, 1000},
+		{"continuous at start", continuous, start, 1000},
+		{"continuous a quarter in", continuous, start + 100, 750},
+		{"continuous rounds the vested amount down", continuous, start + 1, 998},
+		{"continuous just before end", continuous, end - 1, 3},
+		{"continuous at end", continuous, end, 0},
+		{"continuous after end", continuous, end + 1, 0},
+		{"delayed before end", delayed, end - 1, 1000},
+		{"delayed at end", delayed, end, 0},
+		{"periodic at start", periodic, start, 1000},
+		{"periodic mid first period", periodic, start + 50, 1000},
+		{"periodic at the end of the first period", periodic, start + 100, 900},
+		{"periodic mid third period", periodic, start + 250, 700},
+		{"periodic just before end", periodic, end - 1, 400},
+		{"periodic at end", periodic, end, 0},
+		{"permanent before end", permanent, start, 1000},
+		{"permanent after end", permanent, end + 1, 1000},
+	}
+
+	for _, tt := range tests {
+		if got := tt.account.locked(time.Unix(tt.at, 0)); got.Int64() != tt.want {
+			t.Errorf("%s: locked() = %s, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVestingAccountLockedNeverNegative(t *testing.T) {
+	// Periods vesting more than the original amount, e.g. after a partial clawback.
+	account := &vestingAccount{Kind: periodicVesting, Original: big.NewInt(100), Start: 0, End: 1000, Periods: []vestingPeriod{
+		{Length: 10, Amount: big.NewInt(150)},
+	}}
+	if got := account.locked(time.Unix(500, 0)); got.Sign() != 0 {
+		t.Errorf("locked() = %s, want 0", got)
+	}
+}
+
+func TestVestingAccountLockedIncludesDelegatedVesting(t *testing.T) {
+	// 600 of the 1000 tokens still vesting are delegated: the SDK LockedCoins would be 400.
+	const start, end = int64(1_700_000_000), int64(1_700_000_400)
+	account, err := anypb.New(&vestingv1beta1.ContinuousVestingAccount{
+		BaseVestingAccount: &vestingv1beta1.BaseVestingAccount{
+			BaseAccount:      &authv1beta1.BaseAccount{Address: testAddress(t, bech32Prefix, 1)},
+			OriginalVesting:  []*basev1beta1.Coin{{Denom: "umfx", Amount: "1000"}},
+			DelegatedVesting: []*basev1beta1.Coin{{Denom: "umfx", Amount: "600"}},
+			EndTime:          end,
+		},
+		StartTime: start,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vesting, err := decodeVestingAccount(account, "umfx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vesting.locked(time.Unix(start, 0)); got.Int64() != 1000 {
+		t.Errorf("locked() = %s, want the 1000 unvested tokens, delegated or not", got)
+	}
+}
@@ -0,0 +1,257 @@
+package autodetect
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	gopnet "github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg/collectors"
+)
+
+// processCollectorName is the configuration key of the process collector.
+const processCollectorName = "process"
+
+// ProcessCollector collects the resource usage of a detected process.
+// Statistics the exporter is not permitted to read, e.g. the IO counters of a process owned by another user, are omitted
+// and counted as read errors. The process is reported down when no statistic can be read.
+type ProcessCollector struct {
+	pid                int32
+	cpuDesc            *prometheus.Desc // CPU time, by mode
+	residentMemoryDesc *prometheus.Desc // Resident memory
+	virtualMemoryDesc  *prometheus.Desc // Virtual memory
+	openFdsDesc        *prometheus.Desc // Open file descriptors
+	maxFdsDesc         *prometheus.Desc // File descriptor limit
+	threadsDesc        *prometheus.Desc // OS threads
+	readBytesDesc      *prometheus.Desc // Bytes read from storage
+	writeBytesDesc     *prometheus.Desc // Bytes written to storage
+	startTimeDesc      *prometheus.Desc // Start time
+	tcpConnectionsDesc *prometheus.Desc // TCP connections, by state
+	readErrorsDesc     *prometheus.Desc // Statistic read errors, by statistic
+	upDesc             *prometheus.Desc // Process inspection success
+	initialError       error
+
+	mu         sync.Mutex
+	readErrors map[string]uint64
+}
+
+// NewProcessCollector creates a new ProcessCollector for the process with the given name and PID.
+// The process name is reported in the `process` label of every metric.
+func NewProcessCollector(name string, pid int32) *ProcessCollector {
+	var initialError error
+	if pid <= 0 {
+		initialError = status.Errorf(codes.InvalidArgument, "invalid PID %d", pid)
+	}
+
+	labels := prometheus.Labels{"process": name}
+	return &ProcessCollector{
+		pid:          pid,
+		initialError: initialError,
+		readErrors:   make(map[string]uint64),
+		cpuDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "cpu_seconds_total"),
+			"CPU time spent by the process, in seconds, by mode.",
+			[]string{"mode"},
+			labels,
+		),
+		residentMemoryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "resident_memory_bytes"),
+			"Resident memory size of the process, in bytes.",
+			nil,
+			labels,
+		),
+		virtualMemoryDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "virtual_memory_bytes"),
+			"Virtual memory size of the process, in bytes.",
+			nil,
+			labels,
+		),
+		openFdsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "open_fds"),
+			"Number of open file descriptors of the process.",
+			nil,
+			labels,
+		),
+		maxFdsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "max_fds"),
+			"Maximum number of open file descriptors of the process (soft limit).",
+			nil,
+			labels,
+		),
+		threadsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "threads"),
+			"Number of OS threads of the process.",
+			nil,
+			labels,
+		),
+		readBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "io_read_bytes_total"),
+			"Bytes read from storage by the process.",
+			nil,
+			labels,
+		),
+		writeBytesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "io_write_bytes_total"),
+			"Bytes written to storage by the process.",
+			nil,
+			labels,
+		),
+		startTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "start_time_seconds"),
+			"Start time of the process, in seconds since the Unix epoch.",
+			nil,
+			labels,
+		),
+		tcpConnectionsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "tcp_connections"),
+			"Number of TCP connections of the process, by state.",
+			[]string{"state"},
+			labels,
+		),
+		readErrorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "read_errors_total"),
+			"Total number of failed reads of a process statistic, e.g. for lack of permission, by statistic.",
+			[]string{"statistic"},
+			labels,
+		),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "process", "up"),
+			"Whether the process could be inspected, i.e. at least one of its statistics could be read.",
+			nil,
+			labels,
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *ProcessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpuDesc
+	ch <- c.residentMemoryDesc
+	ch <- c.virtualMemoryDesc
+	ch <- c.openFdsDesc
+	ch <- c.maxFdsDesc
+	ch <- c.threadsDesc
+	ch <- c.readBytesDesc
+	ch <- c.writeBytesDesc
+	ch <- c.startTimeDesc
+	ch <- c.tcpConnectionsDesc
+	ch <- c.readErrorsDesc
+	ch <- c.upDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ProcessCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.initialError != nil {
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.startTimeDesc, c.initialError)
+		return
+	}
+
+	p, err := process.NewProcess(c.pid)
+	if err != nil {
+		err = status.Errorf(codes.NotFound, "failed to inspect process %d: %v", c.pid, err)
+		slog.Error("Failed to inspect process", "pid", c.pid, "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.startTimeDesc, err)
+		return
+	}
+
+	var read readStatus
+	if times, err := p.Times(); c.check(&read, "cpu_times", err) {
+		reportCounterMetric(ch, c.cpuDesc, times.User, "user")
+		reportCounterMetric(ch, c.cpuDesc, times.System, "system")
+	}
+	if memory, err := p.MemoryInfo(); c.check(&read, "memory_info", err) {
+		collectors.ReportGaugeMetric(ch, c.residentMemoryDesc, float64(memory.RSS))
+		collectors.ReportGaugeMetric(ch, c.virtualMemoryDesc, float64(memory.VMS))
+	}
+	if fds, err := p.NumFDs(); c.check(&read, "open_fds", err) {
+		collectors.ReportGaugeMetric(ch, c.openFdsDesc, float64(fds))
+	}
+	if limits, err := p.Rlimit(); c.check(&read, "rlimits", err) {
+		for _, limit := range limits {
+			if limit.Resource == process.RLIMIT_NOFILE {
+				collectors.ReportGaugeMetric(ch, c.maxFdsDesc, float64(limit.Soft))
+			}
+		}
+	}
+	if threads, err := p.NumThreads(); c.check(&read, "threads", err) {
+		collectors.ReportGaugeMetric(ch, c.threadsDesc, float64(threads))
+	}
+	if io, err := p.IOCounters(); c.check(&read, "io_counters", err) {
+		reportCounterMetric(ch, c.readBytesDesc, float64(io.ReadBytes))
+		reportCounterMetric(ch, c.writeBytesDesc, float64(io.WriteBytes))
+	}
+	if createTime, err := p.CreateTime(); c.check(&read, "start_time", err) {
+		collectors.ReportGaugeMetric(ch, c.startTimeDesc, float64(createTime)/1000) // Milliseconds to seconds
+	}
+	if connections, err := gopnet.ConnectionsPid("tcp", c.pid); c.check(&read, "tcp_connections", err) {
+		states := make(map[string]int)
+		for _, conn := range connections {
+			states[conn.Status]++
+		}
+		for state, count := range states {
+			collectors.ReportGaugeMetric(ch, c.tcpConnectionsDesc, float64(count), state)
+		}
+	}
+
+	c.mu.Lock()
+	for statistic, count := range c.readErrors {
+		reportCounterMetric(ch, c.readErrorsDesc, float64(count), statistic)
+	}
+	c.mu.Unlock()
+
+	if read.succeeded == 0 {
+		err := status.Errorf(codes.PermissionDenied, "failed to read any statistic of process %d: %v", c.pid, read.lastError)
+		slog.Error("Failed to inspect process", "pid", c.pid, "error", err)
+		collectors.ReportUpMetric(ch, c.upDesc, 0)
+		collectors.ReportInvalidMetric(ch, c.startTimeDesc, err)
+		return
+	}
+	collectors.ReportUpMetric(ch, c.upDesc, 1)
+}
+
+// readStatus tracks the statistic reads of a collection.
+type readStatus struct {
+	succeeded int
+	lastError error
+}
+
+// check reports whether a statistic was read, counting and logging the error otherwise.
+func (c *ProcessCollector) check(read *readStatus, statistic string, err error) bool {
+	if err != nil {
+		slog.Debug("Failed to read process statistic", "pid", c.pid, "statistic", statistic, "error", err)
+		read.lastError = err
+		c.mu.Lock()
+		c.readErrors[statistic]++
+		c.mu.Unlock()
+		return false
+	}
+	read.succeeded++
+	return true
+}
+
+// reportCounterMetric reports a counter metric, logging an error if it cannot be created.
+func reportCounterMetric(ch chan<- prometheus.Metric, desc *prometheus.Desc, value float64, labelValues ...string) {
+	metric, err := prometheus.NewConstMetric(desc, prometheus.CounterValue, value, labelValues...)
+	if err != nil {
+		slog.Error("Failed to create counter metric", "desc", desc.String(), "error", err)
+		return
+	}
+	ch <- metric
+}
+
+// newProcessCollector creates the resource usage collector of a detected process, instrumented under the name
+// `process_<name>`. It returns nil if the process collector is disabled or its configuration is invalid.
+func newProcessCollector(ctx context.Context, name string, pid int32) prometheus.Collector {
+	instrumentedName := fmt.Sprintf("%s_%s", processCollectorName, name)
+	return collectors.NewConfiguredCollector(ctx, processCollectorName, instrumentedName, func(collectors.CollectorConfig) prometheus.Collector {
+		return NewProcessCollector(name, pid)
+	})
+}
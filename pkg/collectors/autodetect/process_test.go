@@ -0,0 +1,97 @@
+package autodetect
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// collectProcess runs a collection and returns the valid metrics by description, and the number of invalid metrics.
+func collectProcess(c *ProcessCollector) (map[*prometheus.Desc][]*dto.Metric, int) {
+	ch := make(chan prometheus.Metric, 64)
+	c.Collect(ch)
+	close(ch)
+
+	metrics := make(map[*prometheus.Desc][]*dto.Metric)
+	var invalid int
+	for metric := range ch {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err != nil {
+			invalid++
+			continue
+		}
+		metrics[metric.Desc()] = append(metrics[metric.Desc()], m)
+	}
+	return metrics, invalid
+}
+
+func TestProcessCollectorCheck(t *testing.T) {
+	c := NewProcessCollector("manifestd", 42)
+	var read readStatus
+
+	if !c.check(&read, "cpu_times", nil) {
+		t.Error("check() = false for a successful read")
+	}
+	permission := errors.New("permission denied")
+	if c.check(&read, "io_counters", permission) {
+		t.Error("check() = true for a failed read")
+	}
+	c.check(&read, "io_counters", permission)
+
+	if read.succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", read.succeeded)
+	}
+	if read.lastError != permission {
+		t.Errorf("lastError = %v, want %v", read.lastError, permission)
+	}
+	if got := c.readErrors["io_counters"]; got != 2 {
+		t.Errorf("io_counters read errors = %d, want 2", got)
+	}
+	if got := c.readErrors["cpu_times"]; got != 0 {
+		t.Errorf("cpu_times read errors = %d, want 0", got)
+	}
+}
+
+func TestProcessCollectorSelf(t *testing.T) {
+	c := NewProcessCollector("self", int32(os.Getpid()))
+	metrics, invalid := collectProcess(c)
+
+	if invalid != 0 {
+		t.Errorf("invalid metrics = %d, want 0", invalid)
+	}
+	if up := metrics[c.upDesc]; len(up) != 1 || up[0].GetGauge().GetValue() != 1 {
+		t.Fatalf("up = %v, want 1", up)
+	}
+	modes := make(map[string]bool)
+	for _, m := range metrics[c.cpuDesc] {
+		modes[m.GetLabel()[0].GetValue()] = true
+	}
+	if !modes["user"] || !modes["system"] {
+		t.Errorf("cpu_seconds_total modes = %v, want user and system", modes)
+	}
+	if rss := metrics[c.residentMemoryDesc]; len(rss) != 1 || rss[0].GetGauge().GetValue() <= 0 {
+		t.Errorf("resident_memory_bytes = %v, want a positive value", rss)
+	}
+	if fds := metrics[c.openFdsDesc]; len(fds) != 1 || fds[0].GetGauge().GetValue() <= 0 {
+		t.Errorf("open_fds = %v, want a positive value", fds)
+	}
+}
+
+func TestProcessCollectorMissingProcess(t *testing.T) {
+	// PIDs are always below the kernel limit of 2^22.
+	c := NewProcessCollector("missing", 1<<22)
+	metrics, invalid := collectProcess(c)
+
+	if up := metrics[c.upDesc]; len(up) != 1 || up[0].GetGauge().GetValue() != 0 {
+		t.Errorf("up = %v, want 0", up)
+	}
+	if invalid != 1 {
+		t.Errorf("invalid metrics = %d, want 1", invalid)
+	}
+	if len(metrics[c.cpuDesc]) != 0 || len(metrics[c.residentMemoryDesc]) != 0 {
+		t.Error("resource usage reported for a missing process")
+	}
+}
@@ -12,9 +12,10 @@ import (
 
 // ProcessInfo holds information about a detected process.
 type ProcessInfo struct {
-	Pid     int32
+	Pid     int32  // 0 when the process is reached through an explicit endpoint
 	Address string // Primary listening address (e.g., gRPC)
 	Port    uint32 // Primary listening port
+	RPC     string // CometBFT RPC endpoint (host:port), empty when not detected
 }
 
 // ProcessMonitor defines the interface for monitoring a specific process.
@@ -24,6 +25,7 @@ type ProcessMonitor interface {
 	// Detect checks if the process is running and returns its info.
 	Detect() (*ProcessInfo, error)
 	// CollectCollectors registers the collectors for the process.
+	// Resources backing the collectors must be released once the context is cancelled.
 	CollectCollectors(context.Context, *ProcessInfo) ([]prometheus.Collector, error)
 }
 
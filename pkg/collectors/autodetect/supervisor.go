@@ -0,0 +1,182 @@
+package autodetect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// activeMonitor holds the state of a monitor whose process is currently detected.
+type activeMonitor struct {
+	processInfo ProcessInfo
+	collectors  []prometheus.Collector
+	cancel      context.CancelFunc
+}
+
+// Supervisor periodically runs process detection for all registered monitors.
+// Collectors are registered when a process shows up and unregistered when it disappears,
+// or when its PID or listening address changes.
+type Supervisor struct {
+	registerer prometheus.Registerer
+	monitors   []ProcessMonitor
+	interval   time.Duration
+	active     map[string]*activeMonitor
+
+	processCollectors bool // Whether the resource usage of the detected processes is collected
+}
+
+// NewSupervisor creates a new Supervisor for the given monitors.
+// Collectors are registered with the provided Prometheus registerer.
+func NewSupervisor(registerer prometheus.Registerer, monitors []ProcessMonitor, interval time.Duration) (*Supervisor, error) {
+	if len(monitors) == 0 {
+		return nil, fmt.Errorf("no registered monitors found")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("detection interval must be positive, got %s", interval)
+	}
+
+	slog.Info("Registered monitors", "count", len(monitors))
+	for _, monitor := range monitors {
+		slog.Debug("Monitor", "name", monitor.Name())
+	}
+
+	return &Supervisor{
+		registerer: registerer,
+		monitors:   monitors,
+		interval:   interval,
+		active:     make(map[string]*activeMonitor),
+	}, nil
+}
+
+// EnableProcessCollectors adds a process collector to the collectors of every process detected on this host.
+// It must be called before Run.
+func (s *Supervisor) EnableProcessCollectors() {
+	s.processCollectors = true
+}
+
+// Run performs a detection pass immediately and then once per interval, until ctx is cancelled.
+// All collectors are unregistered before Run returns.
+func (s *Supervisor) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.reconcile(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			for name := range s.active {
+				s.deactivate(name)
+			}
+			return
+		case <-ticker.C:
+			s.reconcile(ctx)
+		}
+	}
+}
+
+// reconcile runs detection for every monitor and updates the registered collectors accordingly.
+func (s *Supervisor) reconcile(ctx context.Context) {
+	for _, monitor := range s.monitors {
+		name := monitor.Name()
+		slog.Debug("Attempting to detect process", "name", name)
+
+		processInfo, err := monitor.Detect()
+		if err != nil {
+			slog.Error("Failed to detect process", "name", name, "error", err)
+		}
+
+		current, isActive := s.active[name]
+		switch {
+		case processInfo == nil:
+			if isActive {
+				slog.Info("Process is gone, removing its collectors", "name", name, "pid", current.processInfo.Pid)
+				s.deactivate(name)
+			}
+			continue
+		case isActive && !changed(current.processInfo, *processInfo):
+			continue
+		case isActive:
+			slog.Info("Process changed, refreshing its collectors", "name", name,
+				"old_pid", current.processInfo.Pid, "new_pid", processInfo.Pid,
+				"old_port", current.processInfo.Port, "new_port", processInfo.Port,
+				"old_rpc", current.processInfo.RPC, "new_rpc", processInfo.RPC)
+			s.deactivate(name)
+		}
+
+		s.activate(ctx, monitor, processInfo)
+	}
+}
+
+// changed reports whether a detected process differs from the active one.
+// A process is identified by its PID and listening address. The RPC endpoint only counts when a new endpoint is
+// detected: a failed RPC probe keeps the last known endpoint, so that it does not recreate the collectors of the process.
+func changed(current, detected ProcessInfo) bool {
+	if current.Pid != detected.Pid || current.Address != detected.Address || current.Port != detected.Port {
+		return true
+	}
+	return detected.RPC != "" && detected.RPC != current.RPC
+}
+
+// activate creates and registers the collectors of a newly detected process.
+func (s *Supervisor) activate(ctx context.Context, monitor ProcessMonitor, processInfo *ProcessInfo) {
+	name := monitor.Name()
+	monitorCtx, cancel := context.WithCancel(ctx)
+
+	collectors, err := monitor.CollectCollectors(monitorCtx, processInfo)
+	if err != nil {
+		slog.Error("Failed to collect collectors", "name", name, "error", err)
+		cancel()
+		return
+	}
+	// The resource usage of the process is only available when it runs on this host.
+	if s.processCollectors && processInfo.Pid != 0 {
+		if collector := newProcessCollector(monitorCtx, name, processInfo.Pid); collector != nil {
+			collectors = append(collectors, collector)
+		}
+	}
+	if len(collectors) == 0 {
+		slog.Warn("No collectors found for monitor", "name", name)
+	}
+
+	var registered []prometheus.Collector
+	for _, collector := range collectors {
+		collectorType := fmt.Sprintf("%T", collector) // Get type for logging
+		if err := s.registerer.Register(collector); err != nil {
+			var alreadyRegistered prometheus.AlreadyRegisteredError
+			if errors.As(err, &alreadyRegistered) {
+				slog.Debug("Collector already registered with Prometheus, skipping registration.", "collector_type", collectorType)
+			} else {
+				slog.Error("Failed to register collector with Prometheus", "collector_type", collectorType, "error", err)
+			}
+			continue
+		}
+		slog.Info("Successfully registered collector with Prometheus.", "collector_type", collectorType)
+		registered = append(registered, collector)
+	}
+
+	slog.Info("Process detected", "name", name, "pid", processInfo.Pid, "address", processInfo.Address, "port", processInfo.Port, "rpc", processInfo.RPC)
+	s.active[name] = &activeMonitor{
+		processInfo: *processInfo,
+		collectors:  registered,
+		cancel:      cancel,
+	}
+}
+
+// deactivate unregisters the collectors of a monitor and releases their resources.
+func (s *Supervisor) deactivate(name string) {
+	current, ok := s.active[name]
+	if !ok {
+		return
+	}
+	for _, collector := range current.collectors {
+		if !s.registerer.Unregister(collector) {
+			slog.Warn("Collector was not registered with Prometheus", "collector_type", fmt.Sprintf("%T", collector))
+		}
+	}
+	current.cancel()
+	delete(s.active, name)
+}
@@ -0,0 +1,68 @@
+package autodetect
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// fakeMonitor detects a process without collectors of its own.
+type fakeMonitor struct {
+	processInfo *ProcessInfo
+}
+
+func (m fakeMonitor) Name() string                  { return "fake" }
+func (m fakeMonitor) Detect() (*ProcessInfo, error) { return m.processInfo, nil }
+func (m fakeMonitor) CollectCollectors(context.Context, *ProcessInfo) ([]prometheus.Collector, error) {
+	return nil, nil
+}
+
+func TestChanged(t *testing.T) {
+	current := ProcessInfo{Pid: 42, Address: "127.0.0.1", Port: 9090, RPC: "127.0.0.1:26657"}
+	tests := []struct {
+		name     string
+		detected ProcessInfo
+		want     bool
+	}{
+		{"same process", current, false},
+		{"failed RPC probe", ProcessInfo{Pid: 42, Address: "127.0.0.1", Port: 9090}, false},
+		{"new RPC endpoint", ProcessInfo{Pid: 42, Address: "127.0.0.1", Port: 9090, RPC: "127.0.0.1:26658"}, true},
+		{"new PID", ProcessInfo{Pid: 43, Address: "127.0.0.1", Port: 9090, RPC: "127.0.0.1:26657"}, true},
+		{"new port", ProcessInfo{Pid: 42, Address: "127.0.0.1", Port: 9091, RPC: "127.0.0.1:26657"}, true},
+	}
+	for _, tt := range tests {
+		if got := changed(current, tt.detected); got != tt.want {
+			t.Errorf("%s: changed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if !changed(ProcessInfo{Pid: 42, Address: "127.0.0.1", Port: 9090}, current) {
+		t.Error("changed() = false for a first detected RPC endpoint, want true")
+	}
+}
+
+func TestSupervisorProcessCollectors(t *testing.T) {
+	monitor := fakeMonitor{processInfo: &ProcessInfo{Pid: int32(os.Getpid())}}
+	for _, enabled := range []bool{false, true} {
+		supervisor, err := NewSupervisor(prometheus.NewRegistry(), []ProcessMonitor{monitor}, time.Minute)
+		if err != nil {
+			t.Fatalf("NewSupervisor() error = %v", err)
+		}
+		if enabled {
+			supervisor.EnableProcessCollectors()
+		}
+
+		supervisor.reconcile(context.Background())
+		want := 0
+		if enabled {
+			want = 1
+		}
+		if got := len(supervisor.active["fake"].collectors); got != want {
+			t.Errorf("process collectors enabled = %v: %d registered collectors, want %d", enabled, got, want)
+		}
+		supervisor.deactivate("fake")
+	}
+}
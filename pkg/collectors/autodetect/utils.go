@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"os/user"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/manifest-network/manifest-node-exporter/pkg/utils"
 	gopnet "github.com/shirou/gopsutil/v4/net"
@@ -128,3 +131,104 @@ func DetectProcessWithGrpc(processName string, defaultPort uint32) (*ProcessInfo
 
 	return nil, fmt.Errorf("no gRPC connection found for %s process (PID %d)", processName, pid)
 }
+
+// DetectRPCPort returns the address (host:port) of the CometBFT RPC endpoint of the process with the given PID.
+// The default port is tried first, then every other listening port. An empty address is returned if none answers.
+func DetectRPCPort(pid int32, defaultPort uint32) (string, error) {
+	ports, err := GetListeningPorts(pid)
+	if err != nil {
+		return "", fmt.Errorf("failed to get listening ports for process %d: %w", pid, err)
+	}
+
+	// Try the default port first, keeping the order of the other ports.
+	slices.SortStableFunc(ports, func(a, b PortInfo) int {
+		switch {
+		case a.Port == defaultPort && b.Port != defaultPort:
+			return -1
+		case a.Port != defaultPort && b.Port == defaultPort:
+			return 1
+		default:
+			return 0
+		}
+	})
+	for _, port := range ports {
+		target := net.JoinHostPort(port.Address, fmt.Sprint(port.Port))
+		if utils.IsCometRPCPort(target) {
+			slog.Debug("CometBFT RPC connection successful", "target", target)
+			return target, nil
+		}
+	}
+
+	slog.Debug("No CometBFT RPC port found", "pid", pid)
+	return "", nil
+}
+
+// DetectGrpcEndpoint builds the process information from an explicitly configured gRPC address.
+// Process and port detection are skipped, so the process may run in another container or on another host.
+// The address must still answer as a Cosmos SDK gRPC endpoint. The returned PID is always 0.
+func DetectGrpcEndpoint(processName, address string) (*ProcessInfo, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC endpoint for %s, expected host:port: %w", processName, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in gRPC endpoint for %s: %w", processName, err)
+	}
+
+	if !utils.IsGrpcPort(address) {
+		return nil, fmt.Errorf("no gRPC connection found for %s at %s", processName, address)
+	}
+
+	slog.Debug("gRPC connection successful", "name", processName, "target", address)
+	return &ProcessInfo{
+		Address: host,
+		Port:    uint32(port),
+	}, nil
+}
+
+// GetProcessFlag returns the value of a command line flag of the process with the given PID.
+// Both the `--flag value` and `--flag=value` forms are supported.
+// It returns false if the flag is not set.
+func GetProcessFlag(pid int32, flag string) (string, bool, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to inspect process %d: %w", pid, err)
+	}
+	args, err := p.CmdlineSlice()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get command line of process %d: %w", pid, err)
+	}
+
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			return args[i+1], true, nil
+		}
+		if value, found := strings.CutPrefix(arg, flag+"="); found {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// GetProcessUserHome returns the home directory of the user running the process with the given PID.
+func GetProcessUserHome(pid int32) (string, error) {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect process %d: %w", pid, err)
+	}
+	uids, err := p.Uids()
+	if err != nil || len(uids) == 0 {
+		return "", fmt.Errorf("failed to get the user of process %d: %v", pid, err)
+	}
+	// The effective user owns the files the process reads.
+	uid := uids[0]
+	if len(uids) > 1 {
+		uid = uids[1]
+	}
+	owner, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the user of process %d: %w", pid, err)
+	}
+	return owner.HomeDir, nil
+}
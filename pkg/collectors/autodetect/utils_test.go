@@ -0,0 +1,79 @@
+package autodetect
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	tmv1beta1 "cosmossdk.io/api/cosmos/base/tendermint/v1beta1"
+	"google.golang.org/grpc"
+)
+
+// fakeNodeInfoServer answers GetNodeInfo with the given Cosmos SDK version.
+type fakeNodeInfoServer struct {
+	tmv1beta1.UnimplementedServiceServer
+	cosmosSdkVersion string
+}
+
+func (s fakeNodeInfoServer) GetNodeInfo(context.Context, *tmv1beta1.GetNodeInfoRequest) (*tmv1beta1.GetNodeInfoResponse, error) {
+	return &tmv1beta1.GetNodeInfoResponse{ApplicationVersion: &tmv1beta1.VersionInfo{CosmosSdkVersion: s.cosmosSdkVersion}}, nil
+}
+
+// newNodeInfoServer starts a gRPC server answering GetNodeInfo and returns its address.
+func newNodeInfoServer(t *testing.T, cosmosSdkVersion string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	tmv1beta1.RegisterServiceServer(server, fakeNodeInfoServer{cosmosSdkVersion: cosmosSdkVersion})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestDetectGrpcEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		address func(t *testing.T) string
+		wantErr bool
+	}{
+		{
+			name:    "Cosmos SDK gRPC endpoint",
+			address: func(t *testing.T) string { return newNodeInfoServer(t, "v0.50.13") },
+		},
+		{
+			name:    "gRPC endpoint of another service",
+			address: func(t *testing.T) string { return newNodeInfoServer(t, "") },
+			wantErr: true,
+		},
+		{
+			name:    "missing port",
+			address: func(*testing.T) string { return "127.0.0.1" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			address: func(*testing.T) string { return "127.0.0.1:99999" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := tt.address(t)
+			got, err := DetectGrpcEndpoint("manifestd", address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectGrpcEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if target := net.JoinHostPort(got.Address, strconv.Itoa(int(got.Port))); target != address || got.Pid != 0 {
+				t.Errorf("DetectGrpcEndpoint() = %+v, want %s without a PID", got, address)
+			}
+		})
+	}
+}
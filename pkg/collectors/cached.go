@@ -0,0 +1,114 @@
+package collectors
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CachedCollector refreshes a collector in the background and serves its last snapshot on scrape.
+// Scrapes never reach the node, so several Prometheus replicas scraping the exporter do not multiply the load.
+type CachedCollector struct {
+	name            string
+	collector       prometheus.Collector
+	interval        time.Duration
+	lastSuccessDesc *prometheus.Desc // Time of the last successful refresh
+	snapshotAgeDesc *prometheus.Desc // Age of the served snapshot
+
+	mu           sync.RWMutex
+	snapshot     []prometheus.Metric
+	snapshotTime time.Time
+	lastSuccess  time.Time
+}
+
+// NewCachedCollector creates a new CachedCollector wrapping the given collector.
+// The collector is refreshed immediately and then once per interval, until ctx is cancelled.
+func NewCachedCollector(ctx context.Context, name string, collector prometheus.Collector, interval time.Duration) *CachedCollector {
+	c := &CachedCollector{
+		name:      name,
+		collector: collector,
+		interval:  interval,
+		lastSuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "exporter", "last_success_timestamp"),
+			"Unix timestamp of the last successful background refresh of the collector.",
+			nil,
+			prometheus.Labels{"collector": name},
+		),
+		snapshotAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "exporter", "snapshot_age_seconds"),
+			"Age of the collector snapshot served on scrape.",
+			nil,
+			prometheus.Labels{"collector": name},
+		),
+	}
+
+	go c.run(ctx)
+
+	return c
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *CachedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	ch <- c.lastSuccessDesc
+	ch <- c.snapshotAgeDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+// It emits the last snapshot without querying the underlying collector.
+func (c *CachedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.snapshotTime.IsZero() {
+		// No refresh has completed yet.
+		return
+	}
+
+	for _, metric := range c.snapshot {
+		ch <- metric
+	}
+
+	lastSuccess := 0.0
+	if !c.lastSuccess.IsZero() {
+		lastSuccess = float64(c.lastSuccess.UnixNano()) / 1e9
+	}
+	ReportGaugeMetric(ch, c.lastSuccessDesc, lastSuccess)
+	ReportGaugeMetric(ch, c.snapshotAgeDesc, time.Since(c.snapshotTime).Seconds())
+}
+
+func (c *CachedCollector) run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.refresh()
+		}
+	}
+}
+
+// refresh collects a new snapshot from the underlying collector.
+// The snapshot is always replaced, so that errors stay visible, but only a refresh without errors counts as a success.
+func (c *CachedCollector) refresh() {
+	metrics, err := GatherMetrics(c.collector)
+	if err != nil {
+		slog.Warn("Background refresh failed", "collector", c.name, "error", err)
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot = metrics
+	c.snapshotTime = now
+	if err == nil {
+		c.lastSuccess = now
+	}
+}
@@ -0,0 +1,76 @@
+package collectors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// fakeCollector emits a gauge with its value, and an invalid metric while it fails.
+type fakeCollector struct {
+	desc  *prometheus.Desc
+	value float64
+	err   error
+}
+
+func newFakeCollector() *fakeCollector {
+	return &fakeCollector{desc: prometheus.NewDesc("fake_value", "Fake value.", nil, nil)}
+}
+
+func (c *fakeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *fakeCollector) Collect(ch chan<- prometheus.Metric) {
+	ReportGaugeMetric(ch, c.desc, c.value)
+	if c.err != nil {
+		ReportInvalidMetric(ch, c.desc, c.err)
+	}
+}
+
+// gaugeValue returns the value of a gauge metric.
+func gaugeValue(t *testing.T, metric prometheus.Metric) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return m.GetGauge().GetValue()
+}
+
+func TestCachedCollectorRefresh(t *testing.T) {
+	fake := newFakeCollector()
+	c := &CachedCollector{name: "fake", collector: fake}
+
+	fake.value = 1
+	c.refresh()
+	firstSuccess := c.lastSuccess
+	if firstSuccess.IsZero() {
+		t.Fatal("lastSuccess not set after a successful refresh")
+	}
+
+	// A refresh with a partial error replaces the snapshot but is not a success.
+	fake.value = 2
+	fake.err = errors.New("one account failed")
+	c.refresh()
+	if len(c.snapshot) != 2 {
+		t.Fatalf("snapshot has %d metrics, want 2", len(c.snapshot))
+	}
+	if MetricError(c.snapshot[1]) == nil {
+		t.Error("snapshot does not carry the refresh error")
+	}
+	if value := gaugeValue(t, c.snapshot[0]); value != 2 {
+		t.Errorf("snapshot value = %v, want 2", value)
+	}
+	if !c.lastSuccess.Equal(firstSuccess) {
+		t.Error("lastSuccess advanced on a failed refresh")
+	}
+
+	fake.err = nil
+	c.refresh()
+	if !c.lastSuccess.After(firstSuccess) {
+		t.Error("lastSuccess did not advance on a successful refresh")
+	}
+}
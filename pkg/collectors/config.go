@@ -0,0 +1,115 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+const DefaultDenom = "umfx"            // Default denom of the Manifest chain
+const DefaultTimeout = 2 * time.Second // Default timeout of individual gRPC queries
+
+// CollectorConfig holds the configuration of a single collector.
+// It is read from the `collectors.<name>` section of the configuration file, e.g.
+//
+//	collectors:
+//	  fees:
+//	    enabled: true
+//	    denoms: [umfx]
+//	    interval: 30s
+//	    timeout: 5s
+type CollectorConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Denoms   []string      `mapstructure:"denoms"`
+	Interval time.Duration `mapstructure:"interval"` // Background refresh interval, 0 collects on scrape
+	Timeout  time.Duration `mapstructure:"timeout"`  // Timeout of individual gRPC queries
+	// Options holds the collector-specific settings, see DecodeOptions.
+	Options map[string]interface{} `mapstructure:",remain"`
+}
+
+// optInCollectors holds the names of the collectors that must be enabled explicitly.
+var optInCollectors = make(map[string]bool)
+
+// RegisterOptInCollector makes the named collector disabled unless enabled in the configuration file.
+// It is meant for collectors that only make sense on some nodes, and must be called from an init function.
+func RegisterOptInCollector(name string) {
+	optInCollectors[name] = true
+}
+
+// LoadCollectorConfig reads the configuration of the named collector.
+// Collectors are enabled by default unless registered as opt-in, query the default denom and use the global async interval.
+func LoadCollectorConfig(name string) (CollectorConfig, error) {
+	cfg := CollectorConfig{
+		Enabled:  !optInCollectors[name],
+		Interval: viper.GetDuration("async-interval"),
+		Timeout:  DefaultTimeout,
+	}
+	if err := viper.UnmarshalKey("collectors."+name, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid configuration for collector %s: %w", name, err)
+	}
+	if len(cfg.Denoms) == 0 {
+		cfg.Denoms = []string{DefaultDenom}
+	}
+	if cfg.Timeout <= 0 {
+		return cfg, fmt.Errorf("invalid configuration for collector %s: timeout must be positive", name)
+	}
+	if cfg.Interval < 0 {
+		return cfg, fmt.Errorf("invalid configuration for collector %s: interval must not be negative", name)
+	}
+	return cfg, nil
+}
+
+// NewConfiguredCollector builds the collector configured under `collectors.<name>`, instrumented and cached as configured.
+// The instrumented name is reported in the `collector` label of the self-instrumentation metrics, and differs from
+// the configuration name when several instances of a collector share one configuration.
+// It returns nil if the collector is disabled or its configuration is invalid.
+func NewConfiguredCollector(ctx context.Context, name, instrumentedName string, build func(CollectorConfig) prometheus.Collector) prometheus.Collector {
+	cfg, err := LoadCollectorConfig(name)
+	if err != nil {
+		slog.Error("Skipping collector", "name", name, "error", err)
+		return nil
+	}
+	if !cfg.Enabled {
+		slog.Info("Collector disabled by configuration", "name", name)
+		return nil
+	}
+
+	var collector prometheus.Collector = NewInstrumentedCollector(instrumentedName, build(cfg))
+	// Collectors are refreshed in the background when an interval is configured.
+	if cfg.Interval > 0 {
+		collector = NewCachedCollector(ctx, instrumentedName, collector, cfg.Interval)
+	}
+	return collector
+}
+
+// Denom returns the first configured denom, for collectors reporting a single denom.
+func (c CollectorConfig) Denom() string {
+	if len(c.Denoms) == 0 {
+		return DefaultDenom
+	}
+	return c.Denoms[0]
+}
+
+// DecodeOptions decodes the collector-specific options into out, which must be a pointer to a struct
+// with `mapstructure` tags. Fields absent from the configuration keep their current value.
+// Strings are decoded into types implementing encoding.TextUnmarshaler.
+func (c CollectorConfig) DecodeOptions(out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.TextUnmarshallerHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(c.Options)
+}
@@ -0,0 +1,47 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+func TestNewConfiguredCollector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	build := func(CollectorConfig) prometheus.Collector { return newFakeCollector() }
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   string
+	}{
+		{"default", nil, "instrumented"},
+		{"disabled", map[string]interface{}{"enabled": false}, "nil"},
+		{"invalid", map[string]interface{}{"timeout": "0s"}, "nil"},
+		{"background refresh", map[string]interface{}{"interval": "1m"}, "cached"},
+	}
+	for _, tt := range tests {
+		viper.Reset()
+		if tt.config != nil {
+			viper.Set("collectors.fake", tt.config)
+		}
+
+		got := "nil"
+		switch collector := NewConfiguredCollector(ctx, "fake", "fake_instance", build).(type) {
+		case *InstrumentedCollector:
+			got = "instrumented"
+			if collector.name != "fake_instance" {
+				t.Errorf("%s: instrumented name = %q, want fake_instance", tt.name, collector.name)
+			}
+		case *CachedCollector:
+			got = "cached"
+		}
+		if got != tt.want {
+			t.Errorf("%s: NewConfiguredCollector() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	viper.Reset()
+}
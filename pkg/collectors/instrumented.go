@@ -0,0 +1,107 @@
+package collectors
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InstrumentedCollector wraps a collector and reports how long its collection took,
+// whether it succeeded and how many errors it encountered, broken down by gRPC status code.
+// A collection fails when the wrapped collector emits at least one invalid metric.
+type InstrumentedCollector struct {
+	name         string
+	collector    prometheus.Collector
+	durationDesc *prometheus.Desc // Collection duration
+	successDesc  *prometheus.Desc // Collection success
+	errorsDesc   *prometheus.Desc // Collection errors by gRPC status code
+
+	mu     sync.Mutex
+	errors map[codes.Code]uint64
+}
+
+// NewInstrumentedCollector creates a new InstrumentedCollector wrapping the given collector.
+// The name is reported in the `collector` label of the self-instrumentation metrics.
+func NewInstrumentedCollector(name string, collector prometheus.Collector) *InstrumentedCollector {
+	return &InstrumentedCollector{
+		name:      name,
+		collector: collector,
+		errors:    make(map[codes.Code]uint64),
+		durationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "exporter", "collector_duration_seconds"),
+			"Duration of the collector's last collection.",
+			nil,
+			prometheus.Labels{"collector": name},
+		),
+		successDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "exporter", "collector_success"),
+			"Whether the collector's last collection succeeded.",
+			nil,
+			prometheus.Labels{"collector": name},
+		),
+		errorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("manifest", "exporter", "collector_errors_total"),
+			"Total number of collection errors, by gRPC status code.",
+			[]string{"code"},
+			prometheus.Labels{"collector": name},
+		),
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *InstrumentedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	ch <- c.durationDesc
+	ch <- c.successDesc
+	ch <- c.errorsDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *InstrumentedCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	metrics, _ := GatherMetrics(c.collector)
+	duration := time.Since(start)
+
+	// The same error is usually reported on several metrics; count it once.
+	seen := make(map[string]struct{})
+	c.mu.Lock()
+	for _, metric := range metrics {
+		err := MetricError(metric)
+		if err == nil {
+			continue
+		}
+		if _, ok := seen[err.Error()]; ok {
+			continue
+		}
+		seen[err.Error()] = struct{}{}
+		c.errors[status.Code(err)]++
+	}
+	errorCounts := make(map[codes.Code]uint64, len(c.errors))
+	for code, count := range c.errors {
+		errorCounts[code] = count
+	}
+	c.mu.Unlock()
+
+	for _, metric := range metrics {
+		ch <- metric
+	}
+
+	success := 1.0
+	if len(seen) > 0 {
+		success = 0.0
+	}
+	ReportGaugeMetric(ch, c.durationDesc, duration.Seconds())
+	ReportGaugeMetric(ch, c.successDesc, success)
+	for code, count := range errorCounts {
+		metric, err := prometheus.NewConstMetric(c.errorsDesc, prometheus.CounterValue, float64(count), code.String())
+		if err != nil {
+			slog.Error("Failed to create collector errors metric", "collector", c.name, "error", err)
+		} else {
+			ch <- metric
+		}
+	}
+}
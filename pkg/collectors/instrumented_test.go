@@ -0,0 +1,63 @@
+package collectors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/manifest-network/manifest-node-exporter/pkg"
+)
+
+func TestInstrumentedCollectorScrape(t *testing.T) {
+	failing := newFakeCollector()
+	failing.value = 42
+	failing.err = status.Error(codes.Unavailable, "node unreachable")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewInstrumentedCollector("fake", failing))
+	server := httptest.NewServer(pkg.NewMetricsHandler(registry, registry))
+	defer server.Close()
+
+	for scrape := 1; scrape <= 2; scrape++ {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("GET /metrics error = %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("reading /metrics error = %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /metrics status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+
+		for _, want := range []string{
+			`fake_value 42`,
+			`manifest_exporter_collector_success{collector="fake"} 0`,
+			fmt.Sprintf(`manifest_exporter_collector_errors_total{code="Unavailable",collector="fake"} %d`, scrape),
+		} {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("scrape %d: /metrics does not contain %q:\n%s", scrape, want, body)
+			}
+		}
+	}
+
+	failing.err = nil
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if want := `manifest_exporter_collector_success{collector="fake"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("/metrics does not contain %q:\n%s", want, body)
+	}
+}
@@ -1,9 +1,12 @@
 package collectors
 
 import (
+	"errors"
 	"log/slog"
 
+	queryv1beta1 "cosmossdk.io/api/cosmos/base/query/v1beta1"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -19,6 +22,15 @@ func ReportUpMetric(ch chan<- prometheus.Metric, desc *prometheus.Desc, value fl
 	}
 }
 
+func ReportGaugeMetric(ch chan<- prometheus.Metric, desc *prometheus.Desc, value float64, labelValues ...string) {
+	metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, value, labelValues...)
+	if err != nil {
+		slog.Error("Failed to create gauge metric", "desc", desc.String(), "error", err)
+	} else {
+		ch <- metric
+	}
+}
+
 func ReportInvalidMetric(ch chan<- prometheus.Metric, desc *prometheus.Desc, err error) {
 	ch <- prometheus.NewInvalidMetric(desc, err)
 }
@@ -42,3 +54,52 @@ func ValidateClient(client *client.GRPCClient, initErr error) error {
 	}
 	return nil
 }
+
+// MetricError returns the error carried by an invalid metric, or nil if the metric is valid.
+func MetricError(metric prometheus.Metric) error {
+	return metric.Write(&dto.Metric{})
+}
+
+// GatherMetrics runs the collector synchronously and returns all the metrics it emitted.
+// The errors carried by invalid metrics are joined into the returned error.
+func GatherMetrics(collector prometheus.Collector) ([]prometheus.Metric, error) {
+	ch := make(chan prometheus.Metric)
+	done := make(chan struct{})
+
+	var metrics []prometheus.Metric
+	var errs []error
+	go func() {
+		defer close(done)
+		for metric := range ch {
+			if err := MetricError(metric); err != nil {
+				errs = append(errs, err)
+			}
+			metrics = append(metrics, metric)
+		}
+	}()
+
+	collector.Collect(ch)
+	close(ch)
+	<-done
+
+	return metrics, errors.Join(errs...)
+}
+
+// PageLimit is the number of items requested per page when following pagination.
+const PageLimit = 200
+
+// FetchAllPages calls fetch with successive page requests until the last page has been fetched.
+// The fetch function must return the pagination response of the query it performed.
+func FetchAllPages(fetch func(page *queryv1beta1.PageRequest) (*queryv1beta1.PageResponse, error)) error {
+	var nextKey []byte
+	for {
+		pageResp, err := fetch(&queryv1beta1.PageRequest{Key: nextKey, Limit: PageLimit})
+		if err != nil {
+			return err
+		}
+		if pageResp == nil || len(pageResp.NextKey) == 0 {
+			return nil
+		}
+		nextKey = pageResp.NextKey
+	}
+}
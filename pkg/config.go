@@ -4,40 +4,48 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type ServeConfig struct {
-	ListenAddress string `mapstructure:"listen_address"`
-	IpBaseKey     string `mapstructure:"ipbase_key"`
-	StateFile     string `mapstructure:"state_file"`
+	ListenAddress  string        `mapstructure:"listen_address"`
+	IpBaseKey      string        `mapstructure:"ipbase_key"`
+	StateFile      string        `mapstructure:"state_file"`
+	DetectInterval time.Duration `mapstructure:"detect_interval"`
 }
 
 func (c ServeConfig) Validate() error {
 	host, port, err := net.SplitHostPort(c.ListenAddress)
 	if err != nil {
-		return fmt.Errorf("invalid prometheus-addr format, expected host:port: %w", err)
+		return fmt.Errorf("invalid listen-address format, expected host:port: %w", err)
 	}
 	if _, err := strconv.Atoi(port); err != nil {
-		return fmt.Errorf("invalid port in prometheus-addr: %w", err)
+		return fmt.Errorf("invalid port in listen-address: %w", err)
 	}
 
 	if host != "" && host != "0.0.0.0" && host != "localhost" && net.ParseIP(host) == nil {
-		return fmt.Errorf("invalid host in prometheus-addr: %s", host)
+		return fmt.Errorf("invalid host in listen-address: %s", host)
 	}
 
-	if c.StateFile == "" {
+	// The state file is only used by the GeoIP collector, enabled by the ipbase key.
+	if c.IpBaseKey != "" && c.StateFile == "" {
 		return fmt.Errorf("state-file must be specified")
 	}
 
+	if c.DetectInterval <= 0 {
+		return fmt.Errorf("detect-interval must be positive")
+	}
+
 	return nil
 }
 
 func LoadServeConfig() ServeConfig {
 	return ServeConfig{
-		ListenAddress: viper.GetString("listen-address"),
-		IpBaseKey:     viper.GetString("ipbase-key"),
-		StateFile:     viper.GetString("state-file"),
+		ListenAddress:  viper.GetString("listen-address"),
+		IpBaseKey:      viper.GetString("ipbase-key"),
+		StateFile:      viper.GetString("state-file"),
+		DetectInterval: viper.GetDuration("detect-interval"),
 	}
 }
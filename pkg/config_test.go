@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServeConfigValidate(t *testing.T) {
+	valid := ServeConfig{ListenAddress: "0.0.0.0:2112", DetectInterval: 30 * time.Second}
+	tests := []struct {
+		name    string
+		modify  func(*ServeConfig)
+		wantErr bool
+	}{
+		{"valid", func(*ServeConfig) {}, false},
+		{"GeoIP with state file", func(c *ServeConfig) { c.IpBaseKey, c.StateFile = "key", "./state.json" }, false},
+		{"GeoIP without state file", func(c *ServeConfig) { c.IpBaseKey = "key" }, true},
+		{"missing port", func(c *ServeConfig) { c.ListenAddress = "0.0.0.0" }, true},
+		{"invalid port", func(c *ServeConfig) { c.ListenAddress = "0.0.0.0:http" }, true},
+		{"zero detect interval", func(c *ServeConfig) { c.DetectInterval = 0 }, true},
+		{"negative detect interval", func(c *ServeConfig) { c.DetectInterval = -time.Second }, true},
+	}
+	for _, tt := range tests {
+		config := valid
+		tt.modify(&config)
+		if err := config.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
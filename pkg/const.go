@@ -4,3 +4,4 @@ import "time"
 
 const ClientTimeout = 5 * time.Second // Timeout for HTTP requests
 const ClientRetry = 3                 // Number of retries for HTTP requests
+const SupplyTimeout = 8 * time.Second // Timeout to compute the supply API figures, below the server write timeout
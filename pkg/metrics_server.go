@@ -8,23 +8,25 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // MetricsServer wraps the HTTP server for Prometheus metrics.
 type MetricsServer struct {
 	httpServer *http.Server
+	mux        *http.ServeMux
 	listenAddr string
 }
 
 // NewMetricsServer creates a new MetricsServer instance.
 // It configures an HTTP server to listen on the given address
-// and expose the default Prometheus registry via promhttp.Handler() on "/metrics".
+// and expose the default Prometheus registry via NewMetricsHandler on "/metrics".
 func NewMetricsServer(listenAddr string) *MetricsServer {
 	mux := http.NewServeMux()
 	// Note: Prometheus collectors should be registered *before* the server is started.
 	// This handler uses the default Prometheus registry.
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", NewMetricsHandler(prometheus.DefaultRegisterer, prometheus.DefaultGatherer))
 
 	srv := &http.Server{
 		Addr:         listenAddr,
@@ -36,10 +38,27 @@ func NewMetricsServer(listenAddr string) *MetricsServer {
 
 	return &MetricsServer{
 		httpServer: srv,
+		mux:        mux,
 		listenAddr: listenAddr,
 	}
 }
 
+// NewMetricsHandler returns the handler exposing the metrics of the given gatherer, instrumented with the given registerer.
+// Unlike promhttp.Handler(), it serves the metrics that could be gathered when a collector fails, and logs the errors,
+// so that a single failing collector does not fail the whole scrape.
+func NewMetricsHandler(registerer prometheus.Registerer, gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.InstrumentMetricHandler(registerer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		ErrorHandling: promhttp.ContinueOnError,
+	}))
+}
+
+// Handle registers an additional handler for the given pattern.
+// It must be called before the server is started.
+func (s *MetricsServer) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
 // Start runs the server in a background goroutine.
 // It returns a channel that will receive an error if the server
 // fails to start or stops unexpectedly (excluding http.ErrServerClosed).
@@ -25,6 +25,11 @@ func GetNodeStatus(target string) (*tmv1beta1.GetNodeInfoResponse, error) {
 	}
 	defer conn.Close()
 
+	return QueryNodeInfo(ctx, conn)
+}
+
+// QueryNodeInfo returns the node info, including the application version, over an existing gRPC connection.
+func QueryNodeInfo(ctx context.Context, conn grpc.ClientConnInterface) (*tmv1beta1.GetNodeInfoResponse, error) {
 	client := tmv1beta1.NewServiceClient(conn)
 
 	resp, err := client.GetNodeInfo(ctx, &tmv1beta1.GetNodeInfoRequest{})
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"log/slog"
+	"time"
+
+	"resty.dev/v3"
+)
+
+// cometStatusResponse holds the fields of a CometBFT RPC `status` response used to recognize the RPC port.
+type cometStatusResponse struct {
+	Result struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+	} `json:"result"`
+}
+
+// IsCometRPCPort reports whether the target (host:port) answers as a CometBFT RPC endpoint.
+func IsCometRPCPort(target string) bool {
+	client := resty.New().SetTimeout(2 * time.Second)
+	defer client.Close()
+
+	var status cometStatusResponse
+	if err := DoJSONRequest(client, "http://"+target+"/status", &status); err != nil {
+		return false
+	}
+	if status.Result.NodeInfo.Network == "" {
+		slog.Debug("RPC port is not responding as expected", "target", target, "error", "network is empty")
+		return false
+	}
+
+	slog.Debug("CometBFT RPC port is ready and responding", "target", target, "network", status.Result.NodeInfo.Network, "version", status.Result.NodeInfo.Version)
+	return true
+}